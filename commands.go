@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tamarakaufler/go-crawler/crawler"
+)
+
+var (
+	ErrUnknownCommand = errors.New("Unknown command")
+	ErrWrongArguments = errors.New("Wrong number of arguments")
+	ErrReportFormat   = errors.New("Reports are written as text or json")
+)
+
+// report is the outcome of a command, like a link check or a diff
+type report interface {
+	Display(w io.Writer)
+}
+
+// clickPaths are the shortest click paths the path command finds,
+// each a list of urls
+type clickPaths [][]string
+
+func (p clickPaths) Display(w io.Writer) {
+	crawler.DisplayPaths(w, p)
+}
+
+// command is a subcommand of the CLI. All commands share the flags,
+// given before or after the command name, but before its arguments.
+type command struct {
+	name     string
+	args     string
+	minArgs  int
+	maxArgs  int
+	summary  string
+	help     string
+	examples []string
+	run      func(args []string)
+}
+
+// commands are run as creepycrawly <command> [flags] [arguments],
+// crawl when no command is given
+var commands = []*command{
+	{
+		name:    "crawl",
+		args:    "[<url>]",
+		maxArgs: 1,
+		summary: "crawl a site and display its sitemap and the enabled reports (default)",
+		help: `Crawls the site of the url, the -url flag by default, and displays the sitemap
+and the enabled reports in the -format, text or json.`,
+		examples: []string{
+			"creepycrawly crawl -depth=4 https://docs.docker.com",
+			"creepycrawly -url=https://docs.docker.com -seo -format=json",
+			"creepycrawly crawl -depth=6 -state=./crawl-state",
+		},
+		run: crawl,
+	},
+	{
+		name:    "check",
+		args:    "[<url>]",
+		maxArgs: 1,
+		summary: "crawl a site and report its broken links",
+		help: `Crawls the site of the url and reports links to pages which could not be retrieved,
+with -assets also broken images, scripts and stylesheets, and with -fragments also
+links to missing anchors. Exits with 1 when anything is broken.`,
+		examples: []string{
+			"creepycrawly check -depth=2 https://docs.docker.com",
+			"creepycrawly check -assets -fragments -format=json https://docs.docker.com",
+		},
+		run: check,
+	},
+	{
+		name:    "export",
+		args:    "<crawl>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "convert a saved crawl to text, json, csv, dot or an XML sitemap",
+		help: `Writes a saved crawl, a state directory or its state.json file, to stdout
+in the -format: text or json, as the crawl output, csv, a row per page,
+dot, the link graph for Graphviz, or sitemap, an XML sitemap.`,
+		examples: []string{
+			"creepycrawly export -format=csv ./crawl-state > pages.csv",
+			"creepycrawly export -format=dot ./crawl-state | dot -Tsvg > links.svg",
+			"creepycrawly export -format=json -seo ./crawl-state/state.json",
+		},
+		run: export,
+	},
+	{
+		name:    "sitemap",
+		args:    "[<url>]",
+		maxArgs: 1,
+		summary: "crawl a site and write its sitemap.xml",
+		help: `Crawls the site of the url and writes an XML sitemap of the pages which could
+be retrieved to stdout, with their last modification dates when the site provides them.`,
+		examples: []string{
+			"creepycrawly sitemap -depth=5 https://docs.docker.com > sitemap.xml",
+		},
+		run: sitemap,
+	},
+	{
+		name:    "diff",
+		args:    "<old crawl> <new crawl>",
+		minArgs: 2,
+		maxArgs: 2,
+		summary: "compare two saved crawls",
+		help: `Compares two saved crawls, state directories, their state.json files or the output of
+crawl -format=json, and reports pages added and removed, pages whose links changed,
+new broken links, status code changes and depth changes. Exits with 1 when the crawls differ.`,
+		examples: []string{
+			"creepycrawly diff ./yesterday ./today",
+			"creepycrawly diff ./yesterday/state.json ./today/state.json",
+			"creepycrawly diff yesterday.json today.json",
+		},
+		run: diff,
+	},
+	{
+		name:    "compare",
+		args:    "<first url> <second url>",
+		minArgs: 2,
+		maxArgs: 2,
+		summary: "crawl two sites and compare their structure",
+		help: `Crawls both sites with the same flags and reports pages and links present on one
+but not the other, compared by their paths, as text or, with -format=json, as JSON.
+Exits with 1 when the sites differ.`,
+		examples: []string{
+			"creepycrawly compare -depth=4 https://staging.docs.docker.com https://docs.docker.com",
+			"creepycrawly compare -format=json https://staging.docs.docker.com https://docs.docker.com",
+		},
+		run: compare,
+	},
+	{
+		name:    "path",
+		args:    "<crawl> <to page> [<from page>]",
+		minArgs: 2,
+		maxArgs: 3,
+		summary: "display the shortest click paths to a page of a saved crawl",
+		help: `Displays up to 10 shortest click paths to a page of a saved crawl, from the base url
+or from the given page. Pages can be given as urls or paths. With -format=json the
+paths are written as JSON, each an array of urls. Exits with 1 when there is no path.`,
+		examples: []string{
+			"creepycrawly path ./crawl-state /engine/install/",
+			"creepycrawly path ./crawl-state https://docs.docker.com/engine/install/ /get-started/",
+			"creepycrawly path -format=json ./crawl-state /engine/install/",
+		},
+		run: path,
+	},
+	{
+		name:    "serve",
+		summary: "run crawl jobs submitted over HTTP",
+		help: `Listens on -addr and runs crawl jobs submitted over HTTP, at most -max-jobs
+at a time. See the README for the API.`,
+		examples: []string{
+			"creepycrawly serve -addr=:8080 -max-jobs=4",
+		},
+		run: serve,
+	},
+}
+
+// lookupCommand returns the command of the given name, nil if there is none
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// parseCommand finds the command in the arguments left after parsing
+// the flags given before it, parses the flags given after it and checks
+// its arguments. flag.ErrHelp is returned once help has been displayed.
+func parseCommand(fs *flag.FlagSet, args []string) (*command, []string, error) {
+	name := "crawl"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		return nil, nil, help(fs, args)
+	}
+
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		return nil, nil, fmt.Errorf("%v: %s", ErrUnknownCommand, name)
+	}
+	fs.Usage = func() {
+		cmd.usage(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return cmd, nil, err
+	}
+
+	args = fs.Args()
+	if len(args) < cmd.minArgs || len(args) > cmd.maxArgs {
+		return cmd, nil, ErrWrongArguments
+	}
+	return cmd, args, nil
+}
+
+// help displays the usage of the CLI or of the given command
+func help(fs *flag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		writeUsage(fs)
+		return flag.ErrHelp
+	}
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		return fmt.Errorf("%v: %s", ErrUnknownCommand, args[0])
+	}
+	cmd.usage(fs)
+	return flag.ErrHelp
+}
+
+// usage displays the usage of the CLI
+func usage() {
+	writeUsage(flag.CommandLine)
+}
+
+func writeUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprint(w, "Usage: creepycrawly [<command>] [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun 'creepycrawly help <command>' for the arguments and examples of a command.\n\nFlags, common to all commands:\n")
+	fs.PrintDefaults()
+}
+
+// usage displays the usage of the command
+func (cmd *command) usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: creepycrawly %s [flags] %s\n\n", cmd.name, cmd.args)
+	fmt.Fprintf(w, "%s\n\nExamples:\n", cmd.help)
+	for _, e := range cmd.examples {
+		fmt.Fprintf(w, "  %s\n", e)
+	}
+	fmt.Fprint(w, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+// reportFormat checks the format reports are written in
+func reportFormat(format string) error {
+	switch format {
+	case "", crawler.FormatText, crawler.FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("%v: %s", ErrReportFormat, format)
+}
+
+// writeReport displays the report, or writes it as JSON
+func writeReport(w io.Writer, r report, format string) error {
+	if err := reportFormat(format); err != nil {
+		return err
+	}
+	if format == crawler.FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	r.Display(w)
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCmd  string
+		wantArgs []string
+		wantErr  error
+		wantOut  string
+		depth    int
+	}{
+		{
+			name:     "default command",
+			args:     []string{"-depth=2"},
+			wantCmd:  "crawl",
+			wantArgs: []string{},
+			depth:    2,
+		},
+		{
+			name:     "flags before and after the command",
+			args:     []string{"-depth=2", "check", "-depth=4", "https://docs.docker.com"},
+			wantCmd:  "check",
+			wantArgs: []string{"https://docs.docker.com"},
+			depth:    4,
+		},
+		{
+			name:     "optional argument",
+			args:     []string{"path", "./crawl-state", "/install/", "/get-started/"},
+			wantCmd:  "path",
+			wantArgs: []string{"./crawl-state", "/install/", "/get-started/"},
+			depth:    3,
+		},
+		{
+			name:    "missing argument",
+			args:    []string{"export"},
+			wantErr: ErrWrongArguments,
+		},
+		{
+			name:    "extra argument",
+			args:    []string{"serve", "now"},
+			wantErr: ErrWrongArguments,
+		},
+		{
+			name:    "help",
+			args:    []string{"help"},
+			wantErr: flag.ErrHelp,
+			wantOut: "  sitemap   crawl a site and write its sitemap.xml\n",
+		},
+		{
+			name:    "command help",
+			args:    []string{"help", "diff"},
+			wantErr: flag.ErrHelp,
+			wantOut: "Usage: creepycrawly diff [flags] <old crawl> <new crawl>",
+		},
+		{
+			name:    "flag help",
+			args:    []string{"sitemap", "-h"},
+			wantErr: flag.ErrHelp,
+			wantOut: "creepycrawly sitemap -depth=5 https://docs.docker.com > sitemap.xml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var depth int
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(&out)
+			fs.IntVar(&depth, "depth", 3, "")
+			fs.Parse(tt.args)
+
+			cmd, args, err := parseCommand(fs, fs.Args())
+			if err != tt.wantErr {
+				t.Fatalf("parseCommand() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("parseCommand() output = %s, want %q", out.String(), tt.wantOut)
+			}
+			if err != nil {
+				return
+			}
+			if cmd.name != tt.wantCmd || !reflect.DeepEqual(args, tt.wantArgs) || depth != tt.depth {
+				t.Errorf("parseCommand() = %s %v depth %d, want %s %v depth %d",
+					cmd.name, args, depth, tt.wantCmd, tt.wantArgs, tt.depth)
+			}
+		})
+	}
+}
+
+func TestParseCommand_unknown(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, args := range [][]string{{"crawler"}, {"help", "crawler"}} {
+		if _, _, err := parseCommand(fs, args); err == nil || !strings.HasPrefix(err.Error(), ErrUnknownCommand.Error()) {
+			t.Errorf("parseCommand(%v) error = %v, want %v", args, err, ErrUnknownCommand)
+		}
+	}
+}
+
+// testReport is a report for the tests
+type testReport struct {
+	Pages int `json:"pages"`
+}
+
+func (r *testReport) Display(w io.Writer) {
+	fmt.Fprintf(w, "pages = %d\n", r.Pages)
+}
+
+func TestWriteReport(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{format: "text", want: "pages = 3\n"},
+		{format: "json", want: "{\n  \"pages\": 3\n}\n"},
+		{format: "csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			err := writeReport(&out, &testReport{Pages: 3}, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("writeReport() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestClickPaths(t *testing.T) {
+	paths := clickPaths{{"https://mmmmm.com", "https://mmmmm.com/faq"}}
+	tests := []struct {
+		format string
+		paths  clickPaths
+		want   string
+	}{
+		{format: "json", paths: paths, want: "[\n  [\n    \"https://mmmmm.com\",\n    \"https://mmmmm.com/faq\"\n  ]\n]\n"},
+		{format: "json", paths: clickPaths{}, want: "[]\n"},
+		{format: "text", paths: paths, want: "   - 0 - 1 clicks\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeReport(&out, tt.paths, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("writeReport() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
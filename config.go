@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "CRAWLER_"
+
+var (
+	ErrUnknownOption  = errors.New("Unknown option in the config file")
+	ErrUnknownProfile = errors.New("Profile not found in the config file")
+	ErrNoConfig       = errors.New("Profile given without a config file")
+)
+
+// config is a JSON, YAML or TOML config file. Its options are named as
+// the flags, e.g. {"url": "https://docs.docker.com", "depth": 4,
+// "max-duration": "10m"}. Profiles hold options overriding those at
+// the top level.
+type config struct {
+	options  map[string]json.RawMessage
+	profiles map[string]map[string]json.RawMessage
+}
+
+// applyConfig sets flags not given on the command line from CRAWLER_*
+// environment variables, e.g. CRAWLER_URL or CRAWLER_MAX_PAGES, and then
+// from the config file and its profile. Precedence is flags > environment
+// variables > profile > config file.
+func applyConfig(fs *flag.FlagSet, getenv func(string) string) error {
+	set := map[string]struct{}{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := set[f.Name]; ok || err != nil {
+			return
+		}
+		name := envPrefix + strings.ToUpper(strings.Replace(f.Name, "-", "_", -1))
+		if v := getenv(name); v != "" {
+			if err = fs.Set(f.Name, v); err != nil {
+				err = fmt.Errorf("%s: %v", name, err)
+				return
+			}
+			set[f.Name] = struct{}{}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	file, profile := fs.Lookup("config").Value.String(), fs.Lookup("profile").Value.String()
+	if file == "" {
+		if profile != "" {
+			return ErrNoConfig
+		}
+		return nil
+	}
+	cfg, err := readConfig(file)
+	if err != nil {
+		return err
+	}
+
+	options := cfg.options
+	if profile != "" {
+		p, ok := cfg.profiles[profile]
+		if !ok {
+			return fmt.Errorf("%v: %s", ErrUnknownProfile, profile)
+		}
+		options = map[string]json.RawMessage{}
+		for k, v := range cfg.options {
+			options[k] = v
+		}
+		for k, v := range p {
+			options[k] = v
+		}
+	}
+
+	for name, raw := range options {
+		if fs.Lookup(name) == nil || name == "config" || name == "profile" {
+			return fmt.Errorf("%v: %s", ErrUnknownOption, name)
+		}
+		if _, ok := set[name]; ok {
+			continue
+		}
+		if err := fs.Set(name, optionValue(raw)); err != nil {
+			return fmt.Errorf("%s: %s: %v", file, name, err)
+		}
+	}
+	return nil
+}
+
+// flagGiven returns true if the flag was given on the command line,
+// by an environment variable or in the config file
+func flagGiven(fs *flag.FlagSet, name string) bool {
+	given := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+// readConfig reads a config file, YAML if its extension is .yaml
+// or .yml, TOML if it is .toml and JSON otherwise
+func readConfig(file string) (*config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		data, err = configJSON(data, yaml.Unmarshal)
+	case ".toml":
+		data, err = configJSON(data, toml.Unmarshal)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	cfg := &config{}
+	if err := json.Unmarshal(data, &cfg.options); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if raw, ok := cfg.options["profiles"]; ok {
+		if err := json.Unmarshal(raw, &cfg.profiles); err != nil {
+			return nil, fmt.Errorf("%s: profiles: %v", file, err)
+		}
+		delete(cfg.options, "profiles")
+	}
+	return cfg, nil
+}
+
+// configJSON decodes a YAML or TOML config and encodes it as JSON
+func configJSON(data []byte, unmarshal func([]byte, interface{}) error) ([]byte, error) {
+	options := map[string]interface{}{}
+	if err := unmarshal(data, &options); err != nil {
+		return nil, err
+	}
+	return json.Marshal(options)
+}
+
+// optionValue turns a JSON value into a flag value
+func optionValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(raw))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testConfig = `{
+  "url": "https://docs.docker.com",
+  "depth": 4,
+  "max-duration": "30m",
+  "seo": true,
+  "profiles": {
+    "staging": {"url": "https://staging.docs.docker.com", "max-pages": 500, "seo": false}
+  }
+}`
+
+const testConfigYAML = `url: https://docs.docker.com
+depth: 4
+max-duration: 30m
+seo: true
+profiles:
+  staging:
+    url: https://staging.docs.docker.com
+    max-pages: 500
+    seo: false
+`
+
+const testConfigTOML = `url = "https://docs.docker.com"
+depth = 4
+max-duration = "30m"
+seo = true
+
+[profiles.staging]
+url = "https://staging.docs.docker.com"
+max-pages = 500
+seo = false
+`
+
+type testOptions struct {
+	URL         string
+	Depth       int
+	MaxDuration time.Duration
+	MaxPages    int
+	SEO         bool
+}
+
+func testFlagSet(o *testOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&o.URL, "url", "https://default.com", "")
+	fs.IntVar(&o.Depth, "depth", 3, "")
+	fs.DurationVar(&o.MaxDuration, "max-duration", 0, "")
+	fs.IntVar(&o.MaxPages, "max-pages", 0, "")
+	fs.BoolVar(&o.SEO, "seo", false, "")
+	fs.String("config", "", "")
+	fs.String("profile", "", "")
+	return fs
+}
+
+func TestApplyConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "crawl.json")
+	ioutil.WriteFile(file, []byte(testConfig), 0644)
+	bad := filepath.Join(dir, "bad.json")
+	ioutil.WriteFile(bad, []byte(`{"url": "https://docs.docker.com", "colour": "red"}`), 0644)
+	yaml := filepath.Join(dir, "crawl.yaml")
+	ioutil.WriteFile(yaml, []byte(testConfigYAML), 0644)
+	toml := filepath.Join(dir, "crawl.toml")
+	ioutil.WriteFile(toml, []byte(testConfigTOML), 0644)
+	badYAML := filepath.Join(dir, "bad.yml")
+	ioutil.WriteFile(badYAML, []byte("url: [https://docs.docker.com\n"), 0644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    testOptions
+		wantErr bool
+	}{
+		{
+			name: "no config",
+			args: []string{},
+			want: testOptions{URL: "https://default.com", Depth: 3},
+		},
+		{
+			name: "config file",
+			args: []string{"-config=" + file},
+			want: testOptions{URL: "https://docs.docker.com", Depth: 4, MaxDuration: 30 * time.Minute, SEO: true},
+		},
+		{
+			name: "profile",
+			args: []string{"-config=" + file, "-profile=staging"},
+			want: testOptions{URL: "https://staging.docs.docker.com", Depth: 4, MaxDuration: 30 * time.Minute, MaxPages: 500},
+		},
+		{
+			name: "environment over profile",
+			args: []string{"-config=" + file, "-profile=staging"},
+			env:  map[string]string{"CRAWLER_DEPTH": "2", "CRAWLER_MAX_PAGES": "10"},
+			want: testOptions{URL: "https://staging.docs.docker.com", Depth: 2, MaxDuration: 30 * time.Minute, MaxPages: 10},
+		},
+		{
+			name: "flags over environment",
+			args: []string{"-depth=6", "-seo=false"},
+			env:  map[string]string{"CRAWLER_DEPTH": "2", "CRAWLER_CONFIG": file},
+			want: testOptions{URL: "https://docs.docker.com", Depth: 6, MaxDuration: 30 * time.Minute},
+		},
+		{
+			name:    "unknown profile",
+			args:    []string{"-config=" + file, "-profile=production"},
+			wantErr: true,
+		},
+		{
+			name:    "profile without config",
+			args:    []string{"-profile=staging"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown option",
+			args:    []string{"-config=" + bad},
+			wantErr: true,
+		},
+		{
+			name: "yaml config",
+			args: []string{"-config=" + yaml},
+			want: testOptions{URL: "https://docs.docker.com", Depth: 4, MaxDuration: 30 * time.Minute, SEO: true},
+		},
+		{
+			name: "yaml profile",
+			args: []string{"-config=" + yaml, "-profile=staging"},
+			want: testOptions{URL: "https://staging.docs.docker.com", Depth: 4, MaxDuration: 30 * time.Minute, MaxPages: 500},
+		},
+		{
+			name: "toml config",
+			args: []string{"-config=" + toml},
+			want: testOptions{URL: "https://docs.docker.com", Depth: 4, MaxDuration: 30 * time.Minute, SEO: true},
+		},
+		{
+			name: "toml profile",
+			args: []string{"-config=" + toml, "-profile=staging"},
+			want: testOptions{URL: "https://staging.docs.docker.com", Depth: 4, MaxDuration: 30 * time.Minute, MaxPages: 500},
+		},
+		{
+			name:    "invalid yaml",
+			args:    []string{"-config=" + badYAML},
+			wantErr: true,
+		},
+		{
+			name:    "invalid environment value",
+			args:    []string{},
+			env:     map[string]string{"CRAWLER_DEPTH": "deep"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testOptions
+			fs := testFlagSet(&got)
+			fs.Parse(tt.args)
+
+			err := applyConfig(fs, func(name string) string {
+				return tt.env[name]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagGiven(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want bool
+	}{
+		{name: "default", args: []string{}, want: false},
+		{name: "flag", args: []string{"-depth=3"}, want: true},
+		{name: "environment", args: []string{}, env: map[string]string{"CRAWLER_DEPTH": "2"}, want: true},
+		{name: "other flag", args: []string{"-max-pages=10"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o testOptions
+			fs := testFlagSet(&o)
+			fs.Parse(tt.args)
+			if err := applyConfig(fs, func(name string) string { return tt.env[name] }); err != nil {
+				t.Fatal(err)
+			}
+			if got := flagGiven(fs, "depth"); got != tt.want {
+				t.Errorf("flagGiven() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	defaultAnalysisTop      = 10
+	defaultAnalysisMaxLinks = 20
+)
+
+// LinkAnalysis describes the structure of the crawled site
+type LinkAnalysis struct {
+	// Orphans are pages not linked to from any page but the base URL
+	Orphans []string `json:"orphans"`
+	// DeadEnds are pages without outgoing links within the site
+	DeadEnds []string `json:"dead_ends"`
+	// MostLinked are the pages with the most inbound links
+	MostLinked []PageCount `json:"most_linked"`
+	// ManyLinks are pages with excessive number of outgoing links
+	ManyLinks []PageCount `json:"many_links"`
+	// Components are groups of pages all reachable from each other.
+	// Only groups of more than one page are listed
+	Components [][]string `json:"components"`
+}
+
+// PageCount is a page with the number of its inbound or outgoing links
+type PageCount struct {
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+}
+
+// linkGraph is the link graph of the crawled pages. Self links
+// are left out and nodes are sorted to keep results stable.
+type linkGraph struct {
+	nodes []string
+	out   map[string][]string
+	in    map[string][]string
+}
+
+// graph builds the link graph of the crawled pages
+func (cc *Creeper) graph() *linkGraph {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	return newLinkGraph(cc.seenLinks)
+}
+
+func newLinkGraph(seenLinks map[string][]string) *linkGraph {
+	g := &linkGraph{
+		nodes: sortedPages(seenLinks),
+		out:   map[string][]string{},
+		in:    map[string][]string{},
+	}
+	for _, from := range g.nodes {
+		g.out[from] = []string{}
+		for _, to := range seenLinks[from] {
+			if to == from {
+				continue
+			}
+			g.out[from] = append(g.out[from], to)
+			g.in[to] = append(g.in[to], from)
+		}
+	}
+	return g
+}
+
+// AnalyzeLinks analyses the link graph of the crawled pages, listing
+// top most linked pages and pages with more than maxLinks links
+func (cc *Creeper) AnalyzeLinks(top, maxLinks int) *LinkAnalysis {
+	if top <= 0 {
+		top = defaultAnalysisTop
+	}
+	if maxLinks <= 0 {
+		maxLinks = defaultAnalysisMaxLinks
+	}
+
+	g := cc.graph()
+	a := &LinkAnalysis{
+		Orphans:    []string{},
+		DeadEnds:   []string{},
+		MostLinked: []PageCount{},
+		ManyLinks:  []PageCount{},
+	}
+
+	for _, u := range g.nodes {
+		if u == cc.BaseURL {
+			continue
+		}
+		orphan := true
+		for _, from := range g.in[u] {
+			if from != cc.BaseURL {
+				orphan = false
+				break
+			}
+		}
+		if orphan {
+			a.Orphans = append(a.Orphans, u)
+		}
+	}
+
+	for _, u := range g.nodes {
+		if len(g.out[u]) == 0 {
+			a.DeadEnds = append(a.DeadEnds, u)
+		}
+		if len(g.out[u]) > maxLinks {
+			a.ManyLinks = append(a.ManyLinks, PageCount{URL: u, Count: len(g.out[u])})
+		}
+	}
+	sortPageCounts(a.ManyLinks)
+
+	for u, from := range g.in {
+		a.MostLinked = append(a.MostLinked, PageCount{URL: u, Count: len(from)})
+	}
+	sortPageCounts(a.MostLinked)
+	if len(a.MostLinked) > top {
+		a.MostLinked = a.MostLinked[:top]
+	}
+
+	a.Components = g.components()
+
+	return a
+}
+
+// sortPageCounts sorts by count, highest first, then by url
+func sortPageCounts(pc []PageCount) {
+	sort.Slice(pc, func(i, j int) bool {
+		if pc[i].Count != pc[j].Count {
+			return pc[i].Count > pc[j].Count
+		}
+		return pc[i].URL < pc[j].URL
+	})
+}
+
+// components finds strongly connected components of more than one
+// page, using Tarjan's algorithm
+func (g *linkGraph) components() [][]string {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	components := [][]string{}
+	next := 0
+
+	var connect func(u string)
+	connect = func(u string) {
+		index[u] = next
+		low[u] = next
+		next++
+		stack = append(stack, u)
+		onStack[u] = true
+
+		for _, v := range g.out[u] {
+			if _, ok := g.out[v]; !ok {
+				// link to a page which was not crawled
+				continue
+			}
+			if _, ok := index[v]; !ok {
+				connect(v)
+				if low[v] < low[u] {
+					low[u] = low[v]
+				}
+			} else if onStack[v] && index[v] < low[u] {
+				low[u] = index[v]
+			}
+		}
+
+		if low[u] != index[u] {
+			return
+		}
+		component := []string{}
+		for {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[v] = false
+			component = append(component, v)
+			if v == u {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, u := range g.nodes {
+		if _, ok := index[u]; !ok {
+			connect(u)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
+
+// Display displays the link analysis
+func (a *LinkAnalysis) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 Link analysis 👍\n\n")
+
+	fmt.Fprintf(w, "   orphan pages = %d\n", len(a.Orphans))
+	for _, u := range a.Orphans {
+		fmt.Fprintf(w, "      - [%s]\n", u)
+	}
+	fmt.Fprintf(w, "   dead-end pages = %d\n", len(a.DeadEnds))
+	for _, u := range a.DeadEnds {
+		fmt.Fprintf(w, "      - [%s]\n", u)
+	}
+	fmt.Fprintf(w, "   most linked pages = %d\n", len(a.MostLinked))
+	for _, pc := range a.MostLinked {
+		fmt.Fprintf(w, "      - [%s] (%d inbound links)\n", pc.URL, pc.Count)
+	}
+	fmt.Fprintf(w, "   pages with too many links = %d\n", len(a.ManyLinks))
+	for _, pc := range a.ManyLinks {
+		fmt.Fprintf(w, "      - [%s] (%d links)\n", pc.URL, pc.Count)
+	}
+	fmt.Fprintf(w, "   strongly connected components = %d\n", len(a.Components))
+	for i, c := range a.Components {
+		fmt.Fprintf(w, "      - %d - %d pages\n", i, len(c))
+		for _, u := range c {
+			fmt.Fprintf(w, "         [%s]\n", u)
+		}
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreeper_AnalyzeLinks(t *testing.T) {
+	cc := &Creeper{
+		BaseURL: "https://mmmmm.com",
+		seenLinks: map[string][]string{
+			"https://mmmmm.com":   []string{"https://mmmmm.com/a", "https://mmmmm.com/b", "https://mmmmm.com/d"},
+			"https://mmmmm.com/a": []string{"https://mmmmm.com/b", "https://mmmmm.com/c"},
+			"https://mmmmm.com/b": []string{"https://mmmmm.com/a"},
+			"https://mmmmm.com/c": []string{"https://mmmmm.com/c"},
+			"https://mmmmm.com/d": []string{"https://mmmmm.com/x"},
+		},
+	}
+
+	want := &LinkAnalysis{
+		Orphans:  []string{"https://mmmmm.com/d"},
+		DeadEnds: []string{"https://mmmmm.com/c"},
+		MostLinked: []PageCount{
+			{URL: "https://mmmmm.com/a", Count: 2},
+			{URL: "https://mmmmm.com/b", Count: 2},
+			{URL: "https://mmmmm.com/c", Count: 1},
+		},
+		ManyLinks: []PageCount{
+			{URL: "https://mmmmm.com", Count: 3},
+			{URL: "https://mmmmm.com/a", Count: 2},
+		},
+		Components: [][]string{
+			{"https://mmmmm.com/a", "https://mmmmm.com/b"},
+		},
+	}
+
+	got := cc.AnalyzeLinks(3, 1)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_AnalyzeLinks = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreeper_AnalyzeLinks_mock(t *testing.T) {
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	got := cc.AnalyzeLinks(0, 0)
+	wantDeadEnds := []string{"https://mmmmm.com/generic"}
+	if !reflect.DeepEqual(got.DeadEnds, wantDeadEnds) {
+		t.Errorf("TestCreeper_AnalyzeLinks_mock dead ends = %v, want %v", got.DeadEnds, wantDeadEnds)
+	}
+	wantComponents := [][]string{
+		{"https://mmmmm.com/about", "https://mmmmm.com/faq", "https://mmmmm.com/info"},
+	}
+	if !reflect.DeepEqual(got.Components, wantComponents) {
+		t.Errorf("TestCreeper_AnalyzeLinks_mock components = %v, want %v", got.Components, wantComponents)
+	}
+	if got.MostLinked[0].URL != "https://mmmmm.com/about" || got.MostLinked[0].Count != 3 {
+		t.Errorf("TestCreeper_AnalyzeLinks_mock most linked = %v, want about with 3 links", got.MostLinked[0])
+	}
+}
@@ -0,0 +1,343 @@
+package crawler
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Types of assets referenced by pages
+const (
+	AssetImage      = "img"
+	AssetScript     = "script"
+	AssetStylesheet = "stylesheet"
+	AssetIcon       = "icon"
+	AssetPreload    = "preload"
+	AssetIframe     = "iframe"
+	AssetSource     = "source"
+	AssetVideo      = "video"
+	AssetAudio      = "audio"
+	AssetCSSURL     = "css-url"
+)
+
+var (
+	cssURLRegex      = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+	styleAttrRegex   = regexp.MustCompile(`(?i)\sstyle\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	assetAttrsByType = []struct {
+		tag   string
+		attrs []string
+		typ   string
+	}{
+		{"img", []string{"src", "srcset"}, AssetImage},
+		{"script", []string{"src"}, AssetScript},
+		{"iframe", []string{"src"}, AssetIframe},
+		{"source", []string{"src", "srcset"}, AssetSource},
+		{"video", []string{"src", "poster"}, AssetVideo},
+		{"audio", []string{"src"}, AssetAudio},
+	}
+)
+
+// Asset is a resource referenced by a page
+type Asset struct {
+	URL  string `json:"url"`
+	Type string `json:"type"`
+}
+
+// extractAssets returns resources referenced by a page,
+// in the order of their appearance
+func extractAssets(pageURL, body string) []Asset {
+	assets := []Asset{}
+	seen := map[Asset]struct{}{}
+	add := func(ref, typ, base string) {
+		u, err := resolve(base, ref)
+		if err != nil || ref == "" || !strings.HasPrefix(u, "http") {
+			return
+		}
+		a := Asset{URL: u, Type: typ}
+		if _, ok := seen[a]; ok {
+			return
+		}
+		seen[a] = struct{}{}
+		assets = append(assets, a)
+	}
+
+	for _, t := range assetAttrsByType {
+		for _, tg := range findTags(body, t.tag) {
+			for _, attr := range t.attrs {
+				if attr == "srcset" {
+					for _, ref := range srcset(tg.attrs[attr]) {
+						add(ref, t.typ, pageURL)
+					}
+					continue
+				}
+				add(tg.attrs[attr], t.typ, pageURL)
+			}
+		}
+	}
+
+	for _, tg := range findTags(body, "link") {
+		for _, rel := range strings.Fields(strings.ToLower(tg.attrs["rel"])) {
+			switch rel {
+			case "stylesheet":
+				add(tg.attrs["href"], AssetStylesheet, pageURL)
+			case "icon", "apple-touch-icon":
+				add(tg.attrs["href"], AssetIcon, pageURL)
+			case "preload":
+				add(tg.attrs["href"], AssetPreload, pageURL)
+			}
+		}
+	}
+
+	css := strings.Join(elementTextsRaw(body, "style"), "\n")
+	for _, m := range styleAttrRegex.FindAllStringSubmatch(body, -1) {
+		css += "\n" + html.UnescapeString(m[1]+m[2])
+	}
+	for _, ref := range cssURLs(css) {
+		add(ref, AssetCSSURL, pageURL)
+	}
+
+	return assets
+}
+
+// srcset returns urls of the image candidates of a srcset attribute
+func srcset(s string) []string {
+	refs := []string{}
+	for _, candidate := range strings.Split(s, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			refs = append(refs, fields[0])
+		}
+	}
+	return refs
+}
+
+// cssURLs returns url() references of a stylesheet, except data urls
+func cssURLs(css string) []string {
+	refs := []string{}
+	for _, m := range cssURLRegex.FindAllStringSubmatch(css, -1) {
+		if strings.HasPrefix(strings.TrimSpace(m[1]), "data:") {
+			continue
+		}
+		refs = append(refs, strings.TrimSpace(m[1]))
+	}
+	return refs
+}
+
+// elementTextsRaw returns the unprocessed contents of all elements
+// of the given name
+func elementTextsRaw(body, name string) []string {
+	contents := []string{}
+	for _, m := range elementRegexFor(name).FindAllStringSubmatch(body, -1) {
+		contents = append(contents, m[1])
+	}
+	return contents
+}
+
+// checkAsset returns the status code of an asset, and its content if
+// it is a stylesheet. Other assets are checked with HEAD requests, or,
+// if the server does not support them, with a GET of their first byte.
+func checkAsset(client *http.Client, url string, stylesheet bool) (int, string, error) {
+	if !stylesheet {
+		return checkAssetStatus(client, url)
+	}
+
+	res, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		io.Copy(ioutil.Discard, res.Body)
+		return res.StatusCode, "", nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, "", err
+	}
+	return res.StatusCode, string(body), nil
+}
+
+// checkAssetStatus returns the status code of an asset without
+// downloading it
+func checkAssetStatus(client *http.Client, url string) (int, string, error) {
+	res, err := client.Head(url)
+	if err != nil {
+		return 0, "", err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed && res.StatusCode != http.StatusNotImplemented {
+		return res.StatusCode, "", nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	res, err = client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	io.CopyN(ioutil.Discard, res.Body, 1024)
+	res.Body.Close()
+
+	if res.StatusCode == http.StatusPartialContent {
+		return http.StatusOK, "", nil
+	}
+	return res.StatusCode, "", nil
+}
+
+// recordAssets stores assets of a fetched page, if they are collected,
+// and checks those not checked yet. References found in linked
+// stylesheets become assets of the page too.
+func (cc *Creeper) recordAssets(url, body string) {
+	if !cc.Assets {
+		return
+	}
+	assets := extractAssets(url, body)
+
+	for i := 0; i < len(assets); i++ {
+		a := assets[i]
+
+		// an asset is checked once, pages sharing it
+		// wait for the check to finish
+		cc.muSeen.Lock()
+		done, checking := cc.assetChecks[a.URL]
+		_, checked := cc.assetStatuses[a.URL]
+		if !checking && !checked {
+			done = make(chan struct{})
+			cc.assetChecks[a.URL] = done
+		}
+		cc.muSeen.Unlock()
+
+		switch {
+		case checking:
+			<-done
+		case !checked:
+			status, css, err := checkAsset(cc.httpClient(), a.URL, a.Type == AssetStylesheet)
+			if err != nil {
+				status = 0
+			}
+			cc.budgetSpend(len(css))
+
+			refs := []string{}
+			for _, ref := range cssURLs(css) {
+				if u, err := resolve(a.URL, ref); err == nil && strings.HasPrefix(u, "http") {
+					refs = append(refs, u)
+				}
+			}
+
+			cc.muSeen.Lock()
+			cc.assetStatuses[a.URL] = status
+			if a.Type == AssetStylesheet {
+				cc.cssRefs[a.URL] = refs
+			}
+			cc.muSeen.Unlock()
+			close(done)
+		}
+
+		cc.muSeen.Lock()
+		refs := cc.cssRefs[a.URL]
+		cc.muSeen.Unlock()
+		for _, u := range refs {
+			css := Asset{URL: u, Type: AssetCSSURL}
+			if !containsAsset(assets, css) {
+				assets = append(assets, css)
+			}
+		}
+	}
+
+	cc.muSeen.Lock()
+	cc.assets[url] = assets
+	cc.muSeen.Unlock()
+}
+
+func containsAsset(assets []Asset, a Asset) bool {
+	for _, b := range assets {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// BrokenAsset is an asset, which could not be retrieved.
+// Status is 0 when no response was received
+type BrokenAsset struct {
+	Page   string `json:"page"`
+	URL    string `json:"url"`
+	Type   string `json:"type"`
+	Status int    `json:"status"`
+}
+
+// AssetReport lists assets of the crawled pages
+type AssetReport struct {
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"by_type"`
+	Broken []BrokenAsset  `json:"broken"`
+}
+
+// ReportAssets summarises the assets of the crawled pages
+// and lists those which are broken
+func (cc *Creeper) ReportAssets() *AssetReport {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	r := &AssetReport{
+		ByType: map[string]int{},
+		Broken: []BrokenAsset{},
+	}
+	pages := []string{}
+	for p := range cc.assets {
+		pages = append(pages, p)
+	}
+	sort.Strings(pages)
+
+	unique := map[string]struct{}{}
+	for _, p := range pages {
+		for _, a := range cc.assets[p] {
+			if _, ok := unique[a.URL]; !ok {
+				unique[a.URL] = struct{}{}
+				r.ByType[a.Type]++
+			}
+			status := cc.assetStatuses[a.URL]
+			if status == 0 || status >= http.StatusBadRequest {
+				r.Broken = append(r.Broken, BrokenAsset{
+					Page:   p,
+					URL:    a.URL,
+					Type:   a.Type,
+					Status: status,
+				})
+			}
+		}
+	}
+	r.Total = len(unique)
+
+	return r
+}
+
+// Display displays the assets summary and broken assets
+func (r *AssetReport) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 Assets 👍\n\n")
+
+	types := []string{}
+	for t := range r.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	fmt.Fprintf(w, "   assets = %d\n", r.Total)
+	for _, t := range types {
+		fmt.Fprintf(w, "      - %s = %d\n", t, r.ByType[t])
+	}
+	fmt.Fprintf(w, "   broken assets = %d\n", len(r.Broken))
+	for _, b := range r.Broken {
+		fmt.Fprintf(w, "      ! [%s] -> %s [%s] (status %d)\n", b.Page, b.Type, b.URL, b.Status)
+	}
+	fmt.Fprintln(w)
+}
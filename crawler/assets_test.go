@@ -0,0 +1,196 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExtractAssets(t *testing.T) {
+	body := `<!DOCTYPE html>
+<html>
+  <head>
+    <link rel="stylesheet" href="/static/main.css">
+    <link rel="shortcut icon" href="/favicon.ico">
+    <link rel="preload" href="/fonts/sans.woff2" as="font">
+    <link rel="canonical" href="/about">
+    <script src="https://cdn.mmmmm.com/app.js"></script>
+    <style>
+      body { background: url('/img/bg.png'); }
+      .dot { background: url(data:image/png;base64,iVBORw0KGgo=); }
+    </style>
+  </head>
+  <body>
+    <img src="/img/logo.png" srcset="/img/logo-2x.png 2x, /img/logo-3x.png 3x">
+    <div style="background-image: url(&quot;/img/hero.jpg&quot;)"></div>
+    <iframe src="https://video.mmmmm.com/embed"></iframe>
+    <video src="/media/intro.mp4" poster="/img/poster.jpg">
+      <source src="/media/intro.webm">
+    </video>
+    <audio src="/media/jingle.mp3"></audio>
+    <script>var inline = true;</script>
+  </body>
+</html>`
+
+	want := []Asset{
+		{URL: "https://mmmmm.com/img/logo.png", Type: AssetImage},
+		{URL: "https://mmmmm.com/img/logo-2x.png", Type: AssetImage},
+		{URL: "https://mmmmm.com/img/logo-3x.png", Type: AssetImage},
+		{URL: "https://cdn.mmmmm.com/app.js", Type: AssetScript},
+		{URL: "https://video.mmmmm.com/embed", Type: AssetIframe},
+		{URL: "https://mmmmm.com/media/intro.webm", Type: AssetSource},
+		{URL: "https://mmmmm.com/media/intro.mp4", Type: AssetVideo},
+		{URL: "https://mmmmm.com/img/poster.jpg", Type: AssetVideo},
+		{URL: "https://mmmmm.com/media/jingle.mp3", Type: AssetAudio},
+		{URL: "https://mmmmm.com/static/main.css", Type: AssetStylesheet},
+		{URL: "https://mmmmm.com/favicon.ico", Type: AssetIcon},
+		{URL: "https://mmmmm.com/fonts/sans.woff2", Type: AssetPreload},
+		{URL: "https://mmmmm.com/img/bg.png", Type: AssetCSSURL},
+		{URL: "https://mmmmm.com/img/hero.jpg", Type: AssetCSSURL},
+	}
+	if got := extractAssets("https://mmmmm.com/about", body); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestExtractAssets = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreeper_ReportAssets(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/main.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`h1 { background: url("img/missing.png"); }`))
+	})
+	mux.HandleFunc("/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("png"))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cc := newTestCreeper(ts.URL)
+	cc.Assets = true
+	body := `<link rel="stylesheet" href="/main.css"><img src="/logo.png"><script src="/app.js"></script>`
+	cc.recordAssets(ts.URL+"/about", body)
+	cc.recordAssets(ts.URL+"/faq", `<link rel="stylesheet" href="/main.css">`)
+
+	want := &AssetReport{
+		Total: 4,
+		ByType: map[string]int{
+			AssetImage:      1,
+			AssetScript:     1,
+			AssetStylesheet: 1,
+			AssetCSSURL:     1,
+		},
+		Broken: []BrokenAsset{
+			{Page: ts.URL + "/about", URL: ts.URL + "/app.js", Type: AssetScript, Status: http.StatusNotFound},
+			{Page: ts.URL + "/about", URL: ts.URL + "/img/missing.png", Type: AssetCSSURL, Status: http.StatusNotFound},
+			{Page: ts.URL + "/faq", URL: ts.URL + "/img/missing.png", Type: AssetCSSURL, Status: http.StatusNotFound},
+		},
+	}
+	if got := cc.ReportAssets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_ReportAssets = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckAsset(t *testing.T) {
+	var mu sync.Mutex
+	requests := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte("png"))
+	})
+	mux.HandleFunc("/intro.mp4", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Range"))
+		mu.Unlock()
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("m"))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		want     int
+		requests []string
+	}{
+		{
+			name:     "checked with HEAD",
+			path:     "/logo.png",
+			want:     http.StatusOK,
+			requests: []string{"HEAD /logo.png"},
+		},
+		{
+			name:     "HEAD not allowed, first byte retrieved",
+			path:     "/intro.mp4",
+			want:     http.StatusOK,
+			requests: []string{"HEAD /intro.mp4 ", "GET /intro.mp4 bytes=0-0"},
+		},
+		{
+			name:     "broken",
+			path:     "/missing.png",
+			want:     http.StatusNotFound,
+			requests: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = []string{}
+			got, _, err := checkAsset(http.DefaultClient, ts.URL+tt.path, false)
+			if err != nil {
+				t.Fatalf("checkAsset() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("checkAsset() = %d, want %d", got, tt.want)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("checkAsset() requests = %v, want %v", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestCreeper_ReportAssets_concurrent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/main.css", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`h1 { background: url("img/missing.png"); }`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cc := newTestCreeper(ts.URL)
+	cc.Assets = true
+	pages := []string{"/about", "/faq", "/blog"}
+	var wg sync.WaitGroup
+	for _, p := range pages {
+		wg.Add(1)
+		go func(p string) {
+			defer wg.Done()
+			cc.recordAssets(ts.URL+p, `<link rel="stylesheet" href="/main.css">`)
+		}(p)
+	}
+	wg.Wait()
+
+	// every page sharing the stylesheet gets its references,
+	// whichever page checked it
+	for _, p := range pages {
+		want := []Asset{
+			{URL: ts.URL + "/main.css", Type: AssetStylesheet},
+			{URL: ts.URL + "/img/missing.png", Type: AssetCSSURL},
+		}
+		if got := cc.assets[ts.URL+p]; !reflect.DeepEqual(got, want) {
+			t.Errorf("TestCreeper_ReportAssets_concurrent %s = %+v, want %+v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package crawler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidBasicAuth   = errors.New("Basic auth must be given as user:password")
+	ErrInvalidAuthHeader  = errors.New("Auth header must be given as Name: value")
+	ErrInvalidCookiesFile = errors.New("Invalid line in the cookies file")
+	ErrLoginFailed        = errors.New("Login failed")
+)
+
+// Auth holds the credentials used to crawl pages behind authentication.
+// Basic auth, the bearer token and the header are only sent to the host
+// of the base URL. Cookies, loaded from the cookies file and set by the
+// login, are sent to the domains and paths they are scoped to.
+type Auth struct {
+	// BasicAuth is given as user:password
+	BasicAuth string
+	// BearerToken is sent as Authorization: Bearer <token>
+	BearerToken string
+	// Header is a custom header, given as Name: value
+	Header string
+	// CookiesFile is a Netscape cookies.txt file, as exported by
+	// browsers and written by curl -c
+	CookiesFile string
+	// LoginURL is where LoginForm, url encoded form fields like
+	// user=me&password=secret, is posted before the crawl, to get
+	// the session cookies
+	LoginURL  string
+	LoginForm string
+}
+
+// String describes the auth methods in use, without the credentials
+func (a *Auth) String() string {
+	if a == nil {
+		return "none"
+	}
+	methods := []string{}
+	if a.BasicAuth != "" {
+		methods = append(methods, "basic")
+	}
+	if a.BearerToken != "" {
+		methods = append(methods, "bearer")
+	}
+	if a.Header != "" {
+		methods = append(methods, "header")
+	}
+	if a.CookiesFile != "" {
+		methods = append(methods, "cookies")
+	}
+	if a.LoginURL != "" {
+		methods = append(methods, "login")
+	}
+	if len(methods) == 0 {
+		return "none"
+	}
+	return strings.Join(methods, ",")
+}
+
+// LogValue keeps the credentials out of the logs
+func (a *Auth) LogValue() slog.Value {
+	return slog.StringValue(a.String())
+}
+
+// authTransport adds the credentials to requests to the host
+// of the base URL. Requests the crawl hooks see, and redirects
+// to other hosts, do not carry them.
+type authTransport struct {
+	next   http.RoundTripper
+	host   string
+	user   string
+	pass   string
+	bearer string
+	header [2]string
+}
+
+// RoundTrip sends the request, authenticated if it is for the host
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == t.host {
+		req = req.Clone(req.Context())
+		if t.user != "" {
+			req.SetBasicAuth(t.user, t.pass)
+		}
+		if t.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+t.bearer)
+		}
+		if t.header[0] != "" {
+			req.Header.Set(t.header[0], t.header[1])
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// authSetup adds the credentials to the HTTP client of the crawl,
+// loads the cookies into its cookie jar and logs in
+func (cc *Creeper) authSetup() error {
+	if cc.Auth == nil {
+		return nil
+	}
+	a := cc.Auth
+
+	t := &authTransport{next: cc.client.Transport, host: cc.baseURLParsed.Host, bearer: a.BearerToken}
+	if a.BasicAuth != "" {
+		i := strings.Index(a.BasicAuth, ":")
+		if i < 1 {
+			return ErrInvalidBasicAuth
+		}
+		t.user, t.pass = a.BasicAuth[:i], a.BasicAuth[i+1:]
+	}
+	if a.Header != "" {
+		i := strings.Index(a.Header, ":")
+		if i < 1 {
+			return ErrInvalidAuthHeader
+		}
+		t.header = [2]string{strings.TrimSpace(a.Header[:i]), strings.TrimSpace(a.Header[i+1:])}
+	}
+
+	if a.CookiesFile != "" {
+		if err := loadCookies(cc.client.Jar, a.CookiesFile); err != nil {
+			return err
+		}
+	}
+	cc.client.Transport = t
+
+	if a.LoginURL != "" {
+		return cc.login(a.LoginURL, a.LoginForm)
+	}
+	return nil
+}
+
+// httpClient returns the client requests of the crawl are sent with
+func (cc *Creeper) httpClient() *http.Client {
+	if cc.client != nil {
+		return cc.client
+	}
+	return http.DefaultClient
+}
+
+// login posts the form to the login url. The session cookies it sets,
+// also on redirects, are kept in the cookie jar.
+func (cc *Creeper) login(loginURL, form string) error {
+	values, err := url.ParseQuery(form)
+	if err != nil {
+		return fmt.Errorf("%v: invalid login form", ErrLoginFailed)
+	}
+	res, err := cc.httpClient().PostForm(loginURL, values)
+	if err != nil {
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return fmt.Errorf("%v: %v", ErrLoginFailed, err)
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%v: status %d", ErrLoginFailed, res.StatusCode)
+	}
+	cc.logger().Info("logged in", "url", res.Request.URL.Redacted())
+	return nil
+}
+
+// loadCookies adds the cookies of a Netscape cookies.txt file to the jar.
+// A line holds the domain, whether subdomains are included, the path,
+// whether the cookie is secure, its expiry as a unix time (0 for session
+// cookies), its name and value, separated by tabs. Lines starting with #
+// are comments, except for the #HttpOnly_ prefix of the domain.
+func loadCookies(jar http.CookieJar, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		httpOnly := strings.HasPrefix(line, "#HttpOnly_")
+		if httpOnly {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return fmt.Errorf("%v: %s:%d", ErrInvalidCookiesFile, file, n)
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%v: %s:%d", ErrInvalidCookiesFile, file, n)
+		}
+
+		host := strings.TrimPrefix(fields[0], ".")
+		c := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   fields[3] == "TRUE",
+			HttpOnly: httpOnly,
+		}
+		if fields[1] == "TRUE" {
+			c.Domain = host
+		}
+		if expiry > 0 {
+			c.Expires = time.Unix(expiry, 0)
+		}
+
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: c.Path}, []*http.Cookie{c})
+	}
+	return scanner.Err()
+}
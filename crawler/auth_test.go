@@ -0,0 +1,251 @@
+package crawler
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// authServer serves the mock site to authorized requests
+// and sets the session cookie on login
+func authServer(authorized func(r *http.Request) bool) *httptest.Server {
+	pages := mockHandler(nil)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			if r.PostFormValue("user") != "me" || r.PostFormValue("password") != "secret" {
+				http.Error(w, "wrong credentials", http.StatusForbidden)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/"})
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		if !authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		pages(w, r)
+	}))
+}
+
+func TestCreeper_crawl_auth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name       string
+		authorized func(r *http.Request) bool
+		auth       func(host string) *Auth
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name: "basic auth",
+			authorized: func(r *http.Request) bool {
+				u, p, ok := r.BasicAuth()
+				return ok && u == "me" && p == "secret"
+			},
+			auth:       func(string) *Auth { return &Auth{BasicAuth: "me:secret"} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "wrong password",
+			authorized: func(r *http.Request) bool {
+				u, p, ok := r.BasicAuth()
+				return ok && u == "me" && p == "secret"
+			},
+			auth:       func(string) *Auth { return &Auth{BasicAuth: "me:guess"} },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "bearer token",
+			authorized: func(r *http.Request) bool {
+				return r.Header.Get("Authorization") == "Bearer t0ken"
+			},
+			auth:       func(string) *Auth { return &Auth{BearerToken: "t0ken"} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "custom header",
+			authorized: func(r *http.Request) bool {
+				return r.Header.Get("X-Api-Key") == "k3y"
+			},
+			auth:       func(string) *Auth { return &Auth{Header: "X-Api-Key: k3y"} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "cookies file",
+			authorized: func(r *http.Request) bool {
+				c, err := r.Cookie("session")
+				return err == nil && c.Value == "s3cr3t"
+			},
+			auth: func(host string) *Auth {
+				file := filepath.Join(dir, "cookies.txt")
+				ioutil.WriteFile(file, []byte("# Netscape HTTP Cookie File\n\n"+
+					"#HttpOnly_"+host+"\tFALSE\t/\tFALSE\t0\tsession\ts3cr3t\n"), 0644)
+				return &Auth{CookiesFile: file}
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "form login",
+			authorized: func(r *http.Request) bool {
+				c, err := r.Cookie("session")
+				return err == nil && c.Value == "s3cr3t"
+			},
+			auth: func(host string) *Auth {
+				return &Auth{LoginURL: "http://" + host + "/login", LoginForm: "user=me&password=secret"}
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "failed login",
+			authorized: func(r *http.Request) bool { return false },
+			auth: func(host string) *Auth {
+				return &Auth{LoginURL: "http://" + host + "/login", LoginForm: "user=me&password=guess"}
+			},
+			wantErr: ErrLoginFailed,
+		},
+		{
+			name:       "invalid header",
+			authorized: func(r *http.Request) bool { return false },
+			auth:       func(string) *Auth { return &Auth{Header: "X-Api-Key k3y"} },
+			wantErr:    ErrInvalidAuthHeader,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := authServer(tt.authorized)
+			defer ts.Close()
+
+			var logs bytes.Buffer
+			cc := newTestCreeper(ts.URL)
+			cc.Logger, _ = NewLogger(&logs, "debug", FormatText)
+			cc.Auth = tt.auth(strings.TrimPrefix(ts.URL, "http://"))
+
+			err := cc.clientSetup()
+			if err != nil && !strings.HasPrefix(err.Error(), fmt.Sprint(tt.wantErr)) || err == nil && tt.wantErr != nil {
+				t.Fatalf("TestCreeper_crawl_auth error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			cc.crawl(cc.fetch())
+
+			if got := cc.statuses[ts.URL]; got != tt.wantStatus {
+				t.Errorf("TestCreeper_crawl_auth status = %d, want %d", got, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && len(cc.seenLinks) != 6 {
+				t.Errorf("TestCreeper_crawl_auth crawled %d pages, want 6", len(cc.seenLinks))
+			}
+			for _, secret := range []string{"secret", "t0ken", "k3y", "s3cr3t"} {
+				if strings.Contains(logs.String(), secret) {
+					t.Errorf("TestCreeper_crawl_auth logs contain %q:\n%s", secret, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestAuth_otherHosts(t *testing.T) {
+	var got http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer other.Close()
+
+	cc := newTestCreeper("http://mmmmm.com")
+	cc.Auth = &Auth{BasicAuth: "me:secret", BearerToken: "t0ken", Header: "X-Api-Key: k3y"}
+	if err := cc.clientSetup(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cc.httpClient().Get(other.URL); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "" || got.Get("X-Api-Key") != "" {
+		t.Errorf("TestAuth_otherHosts credentials sent to another host: %v", got)
+	}
+	if s := cc.Auth.String(); s != "basic,bearer,header" {
+		t.Errorf("TestAuth_otherHosts String() = %s, want basic,bearer,header", s)
+	}
+}
+
+func TestLoadCookies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "subdomains",
+			content: ".mmmmm.com\tTRUE\t/\tFALSE\t0\tconsent\tyes\n",
+			url:     "http://docs.mmmmm.com/guide",
+			want:    "consent=yes",
+		},
+		{
+			name:    "path scope",
+			content: "mmmmm.com\tFALSE\t/admin\tFALSE\t0\tconsent\tyes\n",
+			url:     "http://mmmmm.com/guide",
+			want:    "",
+		},
+		{
+			name:    "secure",
+			content: "mmmmm.com\tFALSE\t/\tTRUE\t4102444800\tsession\tabc\n",
+			url:     "https://mmmmm.com/",
+			want:    "session=abc",
+		},
+		{
+			name:    "expired",
+			content: "mmmmm.com\tFALSE\t/\tFALSE\t1000\tsession\tabc\n",
+			url:     "http://mmmmm.com/",
+			want:    "",
+		},
+		{
+			name:    "invalid line",
+			content: "mmmmm.com session abc\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "cookies.txt")
+			ioutil.WriteFile(file, []byte(tt.content), 0644)
+
+			cc := newTestCreeper("http://mmmmm.com")
+			cc.Auth = &Auth{CookiesFile: file}
+			err := cc.clientSetup()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TestLoadCookies error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			u, _ := url.Parse(tt.url)
+			got := ""
+			for _, c := range cc.client.Jar.Cookies(u) {
+				got += c.String()
+			}
+			if got != tt.want {
+				t.Errorf("TestLoadCookies = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package crawler
+
+import (
+	"time"
+)
+
+// Reasons why a crawl ended
+const (
+	EndCompleted   = "completed"
+	EndMaxDuration = "maximum duration reached"
+	EndMaxPages    = "maximum number of pages reached"
+	EndMaxBytes    = "maximum number of bytes reached"
+	EndInterrupted = "interrupted"
+	EndCancelled   = "cancelled"
+)
+
+// budgetStart starts the clock of a crawl, taking into account
+// the time spent before the crawl was resumed
+func (cc *Creeper) budgetStart() {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	cc.started = time.Now().Add(-cc.elapsedBefore)
+	cc.finished = time.Time{}
+	// a crawl cancelled before it started does not start at all
+	if cc.EndReason != EndCancelled {
+		cc.EndReason = ""
+	}
+}
+
+// budgetReserve checks whether the budgets allow another page to be fetched
+// and, if so, books it. Once a budget is exhausted, the reason is recorded
+// and no more pages are allowed.
+func (cc *Creeper) budgetReserve() bool {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	if cc.EndReason != "" {
+		return false
+	}
+	switch {
+	case cc.MaxDuration > 0 && time.Since(cc.started) >= cc.MaxDuration:
+		cc.EndReason = EndMaxDuration
+	case cc.MaxPages > 0 && cc.pagesFetched >= cc.MaxPages:
+		cc.EndReason = EndMaxPages
+	case cc.MaxBytes > 0 && cc.bytesFetched >= cc.MaxBytes:
+		cc.EndReason = EndMaxBytes
+	}
+	if cc.EndReason != "" {
+		return false
+	}
+
+	cc.pagesFetched++
+	return true
+}
+
+// budgetSpend accounts for downloaded bytes
+func (cc *Creeper) budgetSpend(n int) {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	cc.bytesFetched += int64(n)
+}
+
+// budgetRelease gives back a page booked, but not fetched
+func (cc *Creeper) budgetRelease() {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	cc.pagesFetched--
+}
+
+// budgetFail accounts for a page, which could not be fetched
+func (cc *Creeper) budgetFail() {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	cc.pagesFailed++
+}
+
+// budgetExhausted returns true if a budget stopped the crawl
+func (cc *Creeper) budgetExhausted() bool {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	return cc.EndReason != ""
+}
+
+// budgetEnd records a completed crawl, unless a budget stopped it
+func (cc *Creeper) budgetEnd() {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	if cc.EndReason == "" {
+		cc.EndReason = EndCompleted
+	}
+	cc.finished = time.Now()
+}
+
+// crawlTime returns how long the crawl took, or has taken so far
+func (cc *Creeper) crawlTime() time.Duration {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	switch {
+	case cc.started.IsZero():
+		return cc.elapsedBefore
+	case cc.finished.IsZero():
+		return time.Since(cc.started)
+	}
+	return cc.finished.Sub(cc.started)
+}
+
+// Stop cancels a running crawl. Pages already being fetched
+// are still recorded.
+func (cc *Creeper) Stop() {
+	cc.stop(EndCancelled)
+}
+
+// stop stops the crawl for the given reason, unless it already ended
+func (cc *Creeper) stop(reason string) {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	if cc.EndReason == "" {
+		cc.EndReason = reason
+	}
+}
+
+// endReason returns why the crawl ended
+func (cc *Creeper) endReason() string {
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
+	return cc.EndReason
+}
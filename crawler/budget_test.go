@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCreeper_crawl_budgets(t *testing.T) {
+	type fields struct {
+		MaxDuration time.Duration
+		MaxPages    int
+		MaxBytes    int64
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		wantPages     []string
+		wantEndReason string
+	}{
+		// test 1
+		{
+			name:   "no budget",
+			fields: fields{},
+			wantPages: []string{
+				"https://mmmmm.com",
+				"https://mmmmm.com/about",
+				"https://mmmmm.com/careers",
+				"https://mmmmm.com/faq",
+				"https://mmmmm.com/generic",
+				"https://mmmmm.com/info",
+			},
+			wantEndReason: EndCompleted,
+		},
+		// test 2
+		{
+			name: "page budget",
+			fields: fields{
+				MaxPages: 3,
+			},
+			wantPages: []string{
+				"https://mmmmm.com",
+				"https://mmmmm.com/about",
+				"https://mmmmm.com/faq",
+			},
+			wantEndReason: EndMaxPages,
+		},
+		// test 3
+		{
+			name: "byte budget",
+			fields: fields{
+				MaxBytes: 1,
+			},
+			wantPages: []string{
+				"https://mmmmm.com",
+			},
+			wantEndReason: EndMaxBytes,
+		},
+		// test 4
+		{
+			name: "time budget",
+			fields: fields{
+				MaxDuration: time.Nanosecond,
+			},
+			wantPages:     []string{},
+			wantEndReason: EndMaxDuration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &Creeper{
+				BaseURL:       testBaseURL,
+				Depth:         int8(5),
+				Deterministic: true,
+				MaxDuration:   tt.fields.MaxDuration,
+				MaxPages:      tt.fields.MaxPages,
+				MaxBytes:      tt.fields.MaxBytes,
+			}
+			inputCheck(cc)
+			crawlerInit(cc)
+
+			cc.crawl(mockFetch(testBaseURL))
+
+			got := sortedPages(cc.seenLinks)
+			if !reflect.DeepEqual(got, tt.wantPages) {
+				t.Errorf("TestCreeper_crawl_budgets pages = %v, want %v", got, tt.wantPages)
+			}
+			if cc.EndReason != tt.wantEndReason {
+				t.Errorf("TestCreeper_crawl_budgets end reason = %q, want %q", cc.EndReason, tt.wantEndReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+)
+
+// CheckReport lists the broken links of a crawl and, when enabled
+// for the crawler, its broken assets and fragment links
+type CheckReport struct {
+	BaseURL         string         `json:"base_url"`
+	Pages           int            `json:"pages"`
+	BrokenLinks     []BrokenLink   `json:"broken_links"`
+	BrokenAssets    []BrokenAsset  `json:"broken_assets,omitempty"`
+	BrokenFragments []FragmentLink `json:"broken_fragments,omitempty"`
+}
+
+// Check collects the links of the crawled pages to pages which
+// could not be retrieved
+func (cc *Creeper) Check() *CheckReport {
+	st := cc.snapshot()
+	r := &CheckReport{
+		BaseURL:     st.BaseURL,
+		Pages:       len(st.Links),
+		BrokenLinks: brokenLinks(st),
+	}
+	if cc.Assets {
+		r.BrokenAssets = cc.ReportAssets().Broken
+	}
+	if cc.Fragments {
+		r.BrokenFragments = cc.CheckFragments().Broken
+	}
+	return r
+}
+
+// Empty reports whether nothing broken was found
+func (r *CheckReport) Empty() bool {
+	return len(r.BrokenLinks) == 0 && len(r.BrokenAssets) == 0 &&
+		len(r.BrokenFragments) == 0
+}
+
+// Display displays the broken links
+func (r *CheckReport) Display(w io.Writer) {
+	fmt.Fprint(w, "👍 Link check 👍\n\n")
+
+	fmt.Fprintf(w, "   pages checked = %d\n", r.Pages)
+	fmt.Fprintf(w, "   broken links = %d\n", len(r.BrokenLinks))
+	for _, b := range r.BrokenLinks {
+		fmt.Fprintf(w, "      ! [%s] -> [%s] (status %d)\n", b.From, b.To, b.Status)
+	}
+	if r.BrokenAssets != nil {
+		fmt.Fprintf(w, "   broken assets = %d\n", len(r.BrokenAssets))
+		for _, b := range r.BrokenAssets {
+			fmt.Fprintf(w, "      ! [%s] -> %s [%s] (status %d)\n", b.Page, b.Type, b.URL, b.Status)
+		}
+	}
+	if r.BrokenFragments != nil {
+		fmt.Fprintf(w, "   broken fragment links = %d\n", len(r.BrokenFragments))
+		for _, b := range r.BrokenFragments {
+			fmt.Fprintf(w, "      ! [%s] -> [%s]\n", b.Page, b.URL)
+		}
+	}
+
+	fmt.Fprintln(w, "\n👍 The END 👍")
+}
@@ -0,0 +1,49 @@
+package crawler
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreeper_Check(t *testing.T) {
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	r := cc.Check()
+	if !r.Empty() || r.Pages != 6 || r.BrokenAssets != nil || r.BrokenFragments != nil {
+		t.Errorf("TestCreeper_Check = %+v, want 6 pages and nothing broken", r)
+	}
+
+	cc.statuses["https://mmmmm.com/info"] = 404
+	cc.statuses["https://mmmmm.com/careers"] = 0
+	cc.Fragments = true
+
+	r = cc.Check()
+	want := []BrokenLink{
+		{From: "https://mmmmm.com/about", To: "https://mmmmm.com/careers", Status: 0},
+		{From: "https://mmmmm.com/faq", To: "https://mmmmm.com/info", Status: 404},
+	}
+	if r.Empty() || !reflect.DeepEqual(r.BrokenLinks, want) || r.BrokenFragments == nil {
+		t.Errorf("TestCreeper_Check = %+v, want broken links %v", r, want)
+	}
+
+	var out bytes.Buffer
+	r.Display(&out)
+	for _, s := range []string{
+		"broken links = 2",
+		"! [https://mmmmm.com/faq] -> [https://mmmmm.com/info] (status 404)",
+		"broken fragment links = 0",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("TestCreeper_Check display does not contain %q:\n%s", s, out.String())
+		}
+	}
+}
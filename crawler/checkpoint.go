@@ -0,0 +1,296 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNoStateDir = errors.New("No state directory provided")
+)
+
+const (
+	stateFile                 = "state.json"
+	defaultCheckpointInterval = 30 * time.Second
+)
+
+// crawlState is the crawl progress saved in the state directory
+type crawlState struct {
+	BaseURL       string                   `json:"base_url"`
+	Depth         int8                     `json:"depth"`
+	Level         int8                     `json:"level"`
+	Frontier      []string                 `json:"frontier"`
+	Queued        []string                 `json:"queued"`
+	Links         map[string][]string      `json:"links"`
+	Depths        map[string]int8          `json:"depths"`
+	Statuses      map[string]int           `json:"statuses"`
+	Validators    map[string]validator     `json:"validators"`
+	Fingerprints  map[string]fingerprint   `json:"fingerprints,omitempty"`
+	Hashes        map[string]string        `json:"hashes,omitempty"`
+	Metadata      map[string]PageMeta      `json:"metadata,omitempty"`
+	Assets        map[string][]Asset       `json:"assets,omitempty"`
+	Anchors       map[string][]string      `json:"anchors,omitempty"`
+	FragmentLinks map[string][]string      `json:"fragment_links,omitempty"`
+	LinkContexts  map[string][]LinkContext `json:"link_contexts,omitempty"`
+	AssetStatuses map[string]int           `json:"asset_statuses,omitempty"`
+	Changes       map[string]string        `json:"changes,omitempty"`
+	Cookies       []savedCookie            `json:"cookies,omitempty"`
+	PagesFetched  int                      `json:"pages_fetched"`
+	PagesFailed   int                      `json:"pages_failed,omitempty"`
+	BytesFetched  int64                    `json:"bytes_fetched"`
+	Elapsed       time.Duration            `json:"elapsed"`
+}
+
+// checkpointStart saves the crawl state periodically
+// until the returned stop function is called
+func (cc *Creeper) checkpointStart() func() {
+	if cc.StateDir == "" {
+		return func() {}
+	}
+
+	interval := cc.CheckpointInterval
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	ticker := time.NewTicker(interval)
+	quit := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				cc.checkpoint()
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(quit)
+		<-stopped
+		cc.checkpoint()
+	}
+}
+
+// checkpoint saves the crawl state, if a state directory is set up
+func (cc *Creeper) checkpoint() {
+	if cc.StateDir == "" {
+		return
+	}
+	if err := saveState(cc.StateDir, cc.snapshot()); err != nil {
+		cc.logger().Error("crawl state not saved", "dir", cc.StateDir, "error", err)
+	}
+}
+
+// snapshot captures the current crawl progress
+func (cc *Creeper) snapshot() *crawlState {
+	st := &crawlState{
+		BaseURL:       cc.BaseURL,
+		Depth:         cc.Depth,
+		Links:         map[string][]string{},
+		Depths:        map[string]int8{},
+		Statuses:      map[string]int{},
+		Validators:    map[string]validator{},
+		Fingerprints:  map[string]fingerprint{},
+		Hashes:        map[string]string{},
+		Metadata:      map[string]PageMeta{},
+		Assets:        map[string][]Asset{},
+		LinkContexts:  map[string][]LinkContext{},
+		Anchors:       map[string][]string{},
+		FragmentLinks: map[string][]string{},
+		AssetStatuses: map[string]int{},
+		Changes:       map[string]string{},
+	}
+
+	cc.muSeen.Lock()
+	st.Level = cc.level
+	st.Frontier = append([]string{}, cc.frontier...)
+	for u := range cc.queued {
+		st.Queued = append(st.Queued, u)
+	}
+	for u, links := range cc.seenLinks {
+		st.Links[u] = links
+	}
+	for u, d := range cc.seenDepths {
+		st.Depths[u] = d
+	}
+	for u, code := range cc.statuses {
+		st.Statuses[u] = code
+	}
+	for u, v := range cc.validators {
+		st.Validators[u] = v
+	}
+	for u, fp := range cc.fingerprints {
+		st.Fingerprints[u] = fp
+	}
+	for u, h := range cc.hashes {
+		st.Hashes[u] = h
+	}
+	for u, m := range cc.metadata {
+		st.Metadata[u] = m
+	}
+	for u, assets := range cc.assets {
+		st.Assets[u] = assets
+	}
+	for u, status := range cc.assetStatuses {
+		st.AssetStatuses[u] = status
+	}
+	for u, contexts := range cc.linkContexts {
+		st.LinkContexts[u] = contexts
+	}
+	for u, anchors := range cc.anchors {
+		st.Anchors[u] = anchors
+	}
+	for u, links := range cc.fragmentLinks {
+		st.FragmentLinks[u] = links
+	}
+	for u, c := range cc.changes {
+		st.Changes[u] = c
+	}
+	if cc.SaveCookies {
+		keys := []string{}
+		for k := range cc.cookies {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			st.Cookies = append(st.Cookies, cc.cookies[k])
+		}
+	}
+	cc.muSeen.Unlock()
+	sort.Strings(st.Queued)
+
+	cc.muBudget.Lock()
+	st.PagesFetched = cc.pagesFetched
+	st.PagesFailed = cc.pagesFailed
+	st.BytesFetched = cc.bytesFetched
+	if cc.started.IsZero() {
+		st.Elapsed = cc.elapsedBefore
+	} else {
+		st.Elapsed = time.Since(cc.started)
+	}
+	cc.muBudget.Unlock()
+
+	return st
+}
+
+// restoreState sets up the crawler to continue from the saved state,
+// to the depth of the saved crawl
+func (cc *Creeper) restoreState(st *crawlState) {
+	cc.Depth = st.Depth
+
+	cc.muSeen.Lock()
+	cc.level = st.Level
+	cc.frontier = st.Frontier
+	cc.queued = make(map[string]struct{})
+	for _, u := range st.Queued {
+		cc.queued[u] = struct{}{}
+	}
+	for u, links := range st.Links {
+		cc.seenLinks[u] = links
+	}
+	for u, d := range st.Depths {
+		cc.seenDepths[u] = d
+	}
+	for u, code := range st.Statuses {
+		cc.statuses[u] = code
+	}
+	for u, v := range st.Validators {
+		cc.validators[u] = v
+	}
+	for u, fp := range st.Fingerprints {
+		cc.fingerprints[u] = fp
+	}
+	for u, h := range st.Hashes {
+		cc.hashes[u] = h
+	}
+	for u, m := range st.Metadata {
+		cc.metadata[u] = m
+	}
+	for u, assets := range st.Assets {
+		cc.assets[u] = assets
+	}
+	for u, status := range st.AssetStatuses {
+		cc.assetStatuses[u] = status
+	}
+	for u, contexts := range st.LinkContexts {
+		cc.linkContexts[u] = contexts
+	}
+	for u, anchors := range st.Anchors {
+		cc.anchors[u] = anchors
+	}
+	for u, links := range st.FragmentLinks {
+		cc.fragmentLinks[u] = links
+	}
+	for u, c := range st.Changes {
+		cc.changes[u] = c
+	}
+	cc.restoreCookies(st.Cookies)
+	cc.muSeen.Unlock()
+
+	cc.muBudget.Lock()
+	cc.pagesFetched = st.PagesFetched
+	cc.pagesFailed = st.PagesFailed
+	cc.bytesFetched = st.BytesFetched
+	cc.elapsedBefore = st.Elapsed
+	cc.muBudget.Unlock()
+}
+
+// saveState writes the crawl state into the state directory.
+// The state file is replaced atomically, so an interrupted
+// save leaves the previous checkpoint intact.
+func saveState(dir string, st *crawlState) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, stateFile+".")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, stateFile))
+}
+
+// loadState reads the crawl state from the state directory
+func loadState(dir string) (*crawlState, error) {
+	if dir == "" {
+		return nil, ErrNoStateDir
+	}
+	return readStateFile(filepath.Join(dir, stateFile))
+}
+
+// readStateFile reads the crawl state from the given file
+func readStateFile(file string) (*crawlState, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &crawlState{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
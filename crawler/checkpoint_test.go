@@ -0,0 +1,140 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCreeper_crawl_resume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	full := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	inputCheck(full)
+	crawlerInit(full)
+	full.crawl(mockFetch(testBaseURL))
+
+	// interrupted crawl
+	first := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+		MaxPages:      4,
+		StateDir:      dir,
+	}
+	inputCheck(first)
+	crawlerInit(first)
+	first.crawl(mockFetch(testBaseURL))
+
+	if first.EndReason != EndMaxPages {
+		t.Fatalf("TestCreeper_crawl_resume end reason = %q, want %q", first.EndReason, EndMaxPages)
+	}
+
+	// resumed crawl
+	st, err := loadState(dir)
+	if err != nil {
+		t.Fatalf("TestCreeper_crawl_resume loadState error = %v", err)
+	}
+	second := &Creeper{
+		BaseURL:       st.BaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+		StateDir:      dir,
+	}
+	inputCheck(second)
+	crawlerInit(second)
+	second.restoreState(st)
+
+	fetched := []string{}
+	var mu sync.Mutex
+	fetch := mockFetch(testBaseURL)
+	second.crawl(func(url string) (string, error) {
+		mu.Lock()
+		fetched = append(fetched, url)
+		mu.Unlock()
+		return fetch(url)
+	})
+
+	if second.EndReason != EndCompleted {
+		t.Errorf("TestCreeper_crawl_resume end reason = %q, want %q", second.EndReason, EndCompleted)
+	}
+	wantFetched := []string{"https://mmmmm.com/careers", "https://mmmmm.com/generic"}
+	if !reflect.DeepEqual(fetched, wantFetched) {
+		t.Errorf("TestCreeper_crawl_resume fetched = %v, want %v", fetched, wantFetched)
+	}
+	if !reflect.DeepEqual(second.seenLinks, full.seenLinks) {
+		t.Errorf("TestCreeper_crawl_resume = %+v, want %+v", second.seenLinks, full.seenLinks)
+	}
+	if !reflect.DeepEqual(second.seenDepths, full.seenDepths) {
+		t.Errorf("TestCreeper_crawl_resume depths = %+v, want %+v", second.seenDepths, full.seenDepths)
+	}
+	if second.pagesFetched != 6 {
+		t.Errorf("TestCreeper_crawl_resume pages fetched = %d, want 6", second.pagesFetched)
+	}
+}
+
+func TestCreeper_setup_resumeDepth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	full := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(1),
+		Deterministic: true,
+	}
+	inputCheck(full)
+	crawlerInit(full)
+	full.crawl(mockFetch(testBaseURL))
+
+	first := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(1),
+		Deterministic: true,
+		MaxPages:      2,
+		StateDir:      dir,
+	}
+	inputCheck(first)
+	crawlerInit(first)
+	first.crawl(mockFetch(testBaseURL))
+
+	// resumed with another depth
+	second := &Creeper{
+		Depth:         int8(5),
+		Deterministic: true,
+		StateDir:      dir,
+		Resume:        true,
+	}
+	if err := second.setup(); err != nil {
+		t.Fatalf("TestCreeper_setup_resumeDepth setup error = %v", err)
+	}
+	if second.Depth != 1 {
+		t.Errorf("TestCreeper_setup_resumeDepth depth = %d, want 1", second.Depth)
+	}
+	second.crawl(mockFetch(testBaseURL))
+
+	if !reflect.DeepEqual(second.seenLinks, full.seenLinks) {
+		t.Errorf("TestCreeper_setup_resumeDepth = %+v, want %+v", second.seenLinks, full.seenLinks)
+	}
+}
+
+func TestLoadState_missing(t *testing.T) {
+	if _, err := loadState(""); err != ErrNoStateDir {
+		t.Errorf("TestLoadState_missing error = %v, want %v", err, ErrNoStateDir)
+	}
+	if _, err := loadState("./does-not-exist"); err == nil {
+		t.Errorf("TestLoadState_missing expected an error")
+	}
+}
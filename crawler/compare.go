@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// SiteComparison holds structural differences between two sites,
+// e.g. staging and production. Pages and links are compared by
+// their paths, i.e. with the host of each site rewritten away.
+type SiteComparison struct {
+	First             string     `json:"first"`
+	Second            string     `json:"second"`
+	PagesOnlyInFirst  []string   `json:"pages_only_in_first"`
+	PagesOnlyInSecond []string   `json:"pages_only_in_second"`
+	LinksOnlyInFirst  []SiteLink `json:"links_only_in_first"`
+	LinksOnlyInSecond []SiteLink `json:"links_only_in_second"`
+}
+
+// SiteLink is a link between two paths of a site
+type SiteLink struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// CompareSites crawls both sites and compares their structure
+func CompareSites(first, second *Creeper) (*SiteComparison, error) {
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, cc := range []*Creeper{first, second} {
+		wg.Add(1)
+		go func(i int, cc *Creeper) {
+			defer wg.Done()
+			errs[i] = cc.Crawl()
+		}(i, cc)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return compareCrawls(first, second), nil
+}
+
+// compareCrawls compares pages and links collected by two crawls
+func compareCrawls(first, second *Creeper) *SiteComparison {
+	c := &SiteComparison{
+		First:  first.BaseURL,
+		Second: second.BaseURL,
+	}
+
+	firstPages := sitePaths(first)
+	secondPages := sitePaths(second)
+
+	for _, p := range sortedPages(firstPages) {
+		secondLinks, ok := secondPages[p]
+		if !ok {
+			c.PagesOnlyInFirst = append(c.PagesOnlyInFirst, p)
+			continue
+		}
+		onlySecond, onlyFirst := diffLinks(firstPages[p], secondLinks)
+		for _, l := range onlyFirst {
+			c.LinksOnlyInFirst = append(c.LinksOnlyInFirst, SiteLink{From: p, To: l})
+		}
+		for _, l := range onlySecond {
+			c.LinksOnlyInSecond = append(c.LinksOnlyInSecond, SiteLink{From: p, To: l})
+		}
+	}
+	for _, p := range sortedPages(secondPages) {
+		if _, ok := firstPages[p]; !ok {
+			c.PagesOnlyInSecond = append(c.PagesOnlyInSecond, p)
+		}
+	}
+
+	return c
+}
+
+// sitePaths returns the crawled pages and their links as paths
+func sitePaths(cc *Creeper) map[string][]string {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	paths := map[string][]string{}
+	for u, links := range cc.seenLinks {
+		pathLinks := []string{}
+		for _, l := range links {
+			pathLinks = append(pathLinks, sitePath(cc.BaseURL, l))
+		}
+		paths[sitePath(cc.BaseURL, u)] = pathLinks
+	}
+	return paths
+}
+
+// sitePath rewrites a url of the site to its path
+func sitePath(baseURL, u string) string {
+	p := strings.TrimPrefix(u, baseURL)
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// Empty returns true if the sites have the same structure
+func (c *SiteComparison) Empty() bool {
+	return len(c.PagesOnlyInFirst) == 0 && len(c.PagesOnlyInSecond) == 0 &&
+		len(c.LinksOnlyInFirst) == 0 && len(c.LinksOnlyInSecond) == 0
+}
+
+// Display displays the structural differences between the sites
+func (c *SiteComparison) Display(w io.Writer) {
+	fmt.Fprint(w, "👍 Site comparison 👍\n\n")
+
+	for _, side := range []struct {
+		site  string
+		pages []string
+		links []SiteLink
+	}{
+		{c.First, c.PagesOnlyInFirst, c.LinksOnlyInFirst},
+		{c.Second, c.PagesOnlyInSecond, c.LinksOnlyInSecond},
+	} {
+		fmt.Fprintln(w, "================================")
+		fmt.Fprintf(w, "* only on %s\n", side.site)
+		fmt.Fprintf(w, "   pages = %d\n", len(side.pages))
+		for _, p := range side.pages {
+			fmt.Fprintf(w, "      - [%s]\n", p)
+		}
+		fmt.Fprintf(w, "   links = %d\n", len(side.links))
+		for _, l := range side.links {
+			fmt.Fprintf(w, "      - [%s] -> [%s]\n", l.From, l.To)
+		}
+	}
+
+	fmt.Fprintln(w, "\n👍 The END 👍")
+}
@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCompareCrawls(t *testing.T) {
+	production := httptest.NewServer(mockHandler(nil))
+	defer production.Close()
+
+	// staging lost the careers page
+	handler := mockHandler(nil)
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/careers" {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	defer staging.Close()
+
+	first := newTestCreeper(staging.URL)
+	first.crawl(first.fetch())
+	second := newTestCreeper(production.URL)
+	second.crawl(second.fetch())
+
+	want := &SiteComparison{
+		First:             staging.URL,
+		Second:            production.URL,
+		PagesOnlyInSecond: []string{"/careers"},
+	}
+	if got := compareCrawls(first, second); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCompareCrawls = %+v, want %+v", got, want)
+	}
+	if got := compareCrawls(second, second); !got.Empty() {
+		t.Errorf("TestCompareCrawls of the same site = %+v, want no differences", got)
+	}
+}
+
+func TestSiteComparison_json(t *testing.T) {
+	c := &SiteComparison{
+		First:             "https://staging.mmmmm.com",
+		Second:            "https://mmmmm.com",
+		PagesOnlyInSecond: []string{"/careers"},
+		LinksOnlyInSecond: []SiteLink{{From: "/about", To: "/careers"}},
+	}
+	want := `{"first":"https://staging.mmmmm.com","second":"https://mmmmm.com","pages_only_in_first":null,` +
+		`"pages_only_in_second":["/careers"],"links_only_in_first":null,"links_only_in_second":[{"from":"/about","to":"/careers"}]}`
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("SiteComparison json = %s, want %s", data, want)
+	}
+}
+
+func TestCompareCrawls_links(t *testing.T) {
+	first := &Creeper{
+		BaseURL: "https://staging.mmmmm.com",
+		seenLinks: map[string][]string{
+			"https://staging.mmmmm.com":     []string{"https://staging.mmmmm.com/faq"},
+			"https://staging.mmmmm.com/faq": []string{"https://staging.mmmmm.com/new"},
+			"https://staging.mmmmm.com/new": []string{},
+		},
+	}
+	second := &Creeper{
+		BaseURL: "https://mmmmm.com",
+		seenLinks: map[string][]string{
+			"https://mmmmm.com":     []string{"https://mmmmm.com/faq", "https://mmmmm.com/about"},
+			"https://mmmmm.com/faq": []string{},
+		},
+	}
+
+	want := &SiteComparison{
+		First:            "https://staging.mmmmm.com",
+		Second:           "https://mmmmm.com",
+		PagesOnlyInFirst: []string{"/new"},
+		LinksOnlyInFirst: []SiteLink{
+			{From: "/faq", To: "/new"},
+		},
+		LinksOnlyInSecond: []SiteLink{
+			{From: "/", To: "/about"},
+		},
+	}
+	if got := compareCrawls(first, second); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCompareCrawls_links = %+v, want %+v", got, want)
+	}
+}
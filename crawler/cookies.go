@@ -0,0 +1,251 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SiteCookie is a cookie set by the site, without its value
+type SiteCookie struct {
+	Name     string     `json:"name"`
+	Domain   string     `json:"domain"`
+	Path     string     `json:"path"`
+	Secure   bool       `json:"secure"`
+	HttpOnly bool       `json:"http_only"`
+	SameSite string     `json:"same_site,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	// SetBy is the first url which set the cookie
+	SetBy string `json:"set_by"`
+}
+
+// savedCookie is a cookie kept in the crawl state
+type savedCookie struct {
+	SiteCookie
+	Value    string `json:"value"`
+	HostOnly bool   `json:"host_only,omitempty"`
+}
+
+// CookieReport lists the cookies set by the site
+type CookieReport struct {
+	Cookies []SiteCookie `json:"cookies"`
+}
+
+// topLevelDomains is a minimal public suffix list, keeping sites
+// from setting cookies for a whole top-level domain
+type topLevelDomains struct{}
+
+func (topLevelDomains) PublicSuffix(domain string) string {
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+func (topLevelDomains) String() string {
+	return "top-level domains"
+}
+
+// cookieTransport records the cookies set by the responses,
+// including redirects
+type cookieTransport struct {
+	cc   *Creeper
+	next http.RoundTripper
+}
+
+// RoundTrip sends the request and records the cookies of the response
+func (t *cookieTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := roundTrip(t.next, req)
+	if err == nil {
+		t.cc.recordCookies(req.URL, res.Cookies())
+	}
+	return res, err
+}
+
+// clientSetup sets up the HTTP client of the crawl, with a cookie jar
+// shared by all requests, serving the site from the local source if
+// there is one, authenticated if credentials are given
+func (cc *Creeper) clientSetup() error {
+	t := &cookieTransport{cc: cc}
+	if cc.Source != "" {
+		files, err := openSource(cc.Source)
+		if err != nil {
+			return err
+		}
+		t.next = &localTransport{host: cc.baseURLParsed.Host, files: files}
+	}
+
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: topLevelDomains{}})
+	cc.client = &http.Client{Transport: t, Jar: jar}
+
+	// cookies of a resumed crawl go first, so that those
+	// of the cookies file and the login replace them
+	cc.muSeen.Lock()
+	for _, sc := range cc.cookies {
+		jarCookie(jar, sc)
+	}
+	cc.muSeen.Unlock()
+
+	return cc.authSetup()
+}
+
+// recordCookies stores the cookies a response set. Deleted
+// cookies are forgotten.
+func (cc *Creeper) recordCookies(u *url.URL, cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	now := time.Now()
+	for _, c := range cookies {
+		sc := savedCookie{
+			SiteCookie: SiteCookie{
+				Name:     c.Name,
+				Domain:   strings.TrimPrefix(c.Domain, "."),
+				Path:     c.Path,
+				Secure:   c.Secure,
+				HttpOnly: c.HttpOnly,
+				SameSite: sameSite(c.SameSite),
+				SetBy:    u.String(),
+			},
+			Value: c.Value,
+		}
+		if sc.Domain == "" {
+			sc.Domain, sc.HostOnly = u.Hostname(), true
+		}
+		if sc.Path == "" || !strings.HasPrefix(sc.Path, "/") {
+			sc.Path = defaultCookiePath(u.Path)
+		}
+		expires := c.Expires
+		if c.MaxAge > 0 {
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		if !expires.IsZero() {
+			sc.Expires = &expires
+		}
+
+		key := sc.Domain + ";" + sc.Path + ";" + sc.Name
+		if c.MaxAge < 0 || !expires.IsZero() && expires.Before(now) {
+			delete(cc.cookies, key)
+			continue
+		}
+		if prev, ok := cc.cookies[key]; ok {
+			sc.SetBy = prev.SetBy
+		}
+		cc.cookies[key] = sc
+	}
+}
+
+// restoreCookies restores the saved cookies. They are put into
+// the cookie jar when the HTTP client is set up.
+func (cc *Creeper) restoreCookies(cookies []savedCookie) {
+	now := time.Now()
+	for _, sc := range cookies {
+		if sc.Expires != nil && sc.Expires.Before(now) {
+			continue
+		}
+		cc.cookies[sc.Domain+";"+sc.Path+";"+sc.Name] = sc
+	}
+}
+
+// jarCookie puts a saved cookie into the cookie jar
+func jarCookie(jar http.CookieJar, sc savedCookie) {
+	c := &http.Cookie{
+		Name:     sc.Name,
+		Value:    sc.Value,
+		Path:     sc.Path,
+		Secure:   sc.Secure,
+		HttpOnly: sc.HttpOnly,
+	}
+	if !sc.HostOnly {
+		c.Domain = sc.Domain
+	}
+	if sc.Expires != nil {
+		c.Expires = *sc.Expires
+	}
+	scheme := "http"
+	if sc.Secure {
+		scheme = "https"
+	}
+	jar.SetCookies(&url.URL{Scheme: scheme, Host: sc.Domain, Path: sc.Path}, []*http.Cookie{c})
+}
+
+// sameSite names the SameSite attribute of a cookie
+func sameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+// defaultCookiePath is the path of a cookie set without one,
+// the directory of the url path
+func defaultCookiePath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// ReportCookies lists the cookies set by the site during the crawl,
+// by domain, path and name
+func (cc *Creeper) ReportCookies() *CookieReport {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	r := &CookieReport{
+		Cookies: []SiteCookie{},
+	}
+	for _, sc := range cc.cookies {
+		r.Cookies = append(r.Cookies, sc.SiteCookie)
+	}
+	sort.Slice(r.Cookies, func(i, j int) bool {
+		a, b := r.Cookies[i], r.Cookies[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
+	return r
+}
+
+// Display displays the cookies
+func (r *CookieReport) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 Cookies 👍\n\n")
+
+	fmt.Fprintf(w, "   cookies = %d\n", len(r.Cookies))
+	for _, c := range r.Cookies {
+		flags := []string{}
+		if c.Secure {
+			flags = append(flags, "Secure")
+		}
+		if c.HttpOnly {
+			flags = append(flags, "HttpOnly")
+		}
+		if c.SameSite != "" {
+			flags = append(flags, "SameSite="+c.SameSite)
+		}
+		desc := "session"
+		if c.Expires != nil {
+			desc = "expires " + c.Expires.UTC().Format(time.RFC3339)
+		}
+		if len(flags) > 0 {
+			desc = strings.Join(flags, " ") + ", " + desc
+		}
+		fmt.Fprintf(w, "      * %s [%s%s] %s, set by [%s]\n", c.Name, c.Domain, c.Path, desc, c.SetBy)
+	}
+	fmt.Fprintln(w)
+}
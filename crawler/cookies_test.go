@@ -0,0 +1,166 @@
+package crawler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// consentServer redirects visitors without the consent cookie back
+// to the page they asked for, setting the cookie, as consent
+// interstitials do
+func consentServer() http.Handler {
+	pages := mockHandler(nil)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("consent"); err != nil {
+			http.SetCookie(w, &http.Cookie{Name: "consent", Value: "yes", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+			http.Redirect(w, r, r.URL.Path, http.StatusFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/about":
+			http.SetCookie(w, &http.Cookie{Name: "team", Value: "red", Path: "/about", MaxAge: 3600})
+		case "/info":
+			http.SetCookie(w, &http.Cookie{Name: "tracking", Value: "1", Path: "/", Secure: true})
+		case "/generic":
+			http.SetCookie(w, &http.Cookie{Name: "tracking", Value: "", Path: "/", MaxAge: -1})
+		}
+		pages(w, r)
+	})
+}
+
+func TestCreeper_crawl_cookies(t *testing.T) {
+	ts := httptest.NewServer(consentServer())
+	defer ts.Close()
+
+	plain := newTestCreeper(ts.URL)
+	plain.Logger, _ = NewLogger(ioutil.Discard, "error", FormatText)
+	plain.crawl(plain.fetch())
+	if len(plain.seenLinks) != 0 {
+		t.Fatalf("TestCreeper_crawl_cookies crawled %d pages without a cookie jar, want 0", len(plain.seenLinks))
+	}
+
+	cc := newTestCreeper(ts.URL)
+	cc.CookieReport = true
+	if err := cc.clientSetup(); err != nil {
+		t.Fatal(err)
+	}
+	cc.crawl(cc.fetch())
+	if len(cc.seenLinks) != 6 {
+		t.Fatalf("TestCreeper_crawl_cookies crawled %d pages, want 6", len(cc.seenLinks))
+	}
+
+	host := strings.Split(strings.TrimPrefix(ts.URL, "http://"), ":")[0]
+	r := cc.ReportCookies()
+	if len(r.Cookies) != 2 {
+		t.Fatalf("TestCreeper_crawl_cookies cookies = %+v, want consent and team", r.Cookies)
+	}
+	if r.Cookies[1].Expires == nil {
+		t.Errorf("TestCreeper_crawl_cookies team cookie has no expiry")
+	}
+	r.Cookies[1].Expires = nil
+	want := []SiteCookie{
+		{Name: "consent", Domain: host, Path: "/", HttpOnly: true, SameSite: "Lax", SetBy: ts.URL},
+		{Name: "team", Domain: host, Path: "/about", SetBy: ts.URL + "/about"},
+	}
+	if !reflect.DeepEqual(r.Cookies, want) {
+		t.Errorf("TestCreeper_crawl_cookies = %+v, want %+v", r.Cookies, want)
+	}
+
+	var out bytes.Buffer
+	cc.ReportCookies().Display(&out)
+	wantLine := "      * consent [" + host + "/] HttpOnly SameSite=Lax, session, set by [" + ts.URL + "]\n"
+	if !strings.Contains(out.String(), wantLine) || strings.Contains(out.String(), "yes") {
+		t.Errorf("TestCreeper_crawl_cookies display = %s, want %q and no values", out.String(), wantLine)
+	}
+}
+
+func TestCreeper_SaveCookies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := httptest.NewServer(consentServer())
+	defer ts.Close()
+
+	for _, save := range []bool{false, true} {
+		cc := newTestCreeper(ts.URL)
+		cc.StateDir = dir
+		cc.SaveCookies = save
+		cc.clientSetup()
+		cc.crawl(cc.fetch())
+
+		st, err := loadState(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(st.Cookies) > 0; got != save {
+			t.Fatalf("TestCreeper_SaveCookies saved cookies = %v, want %v", got, save)
+		}
+	}
+
+	st, _ := loadState(dir)
+	resumed := newTestCreeper(ts.URL)
+	resumed.restoreState(st)
+	resumed.clientSetup()
+
+	u, _ := url.Parse(ts.URL + "/about")
+	got := []string{}
+	for _, c := range resumed.client.Jar.Cookies(u) {
+		got = append(got, c.String())
+	}
+	want := []string{"team=red", "consent=yes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_SaveCookies restored cookies = %v, want %v", got, want)
+	}
+}
+
+func TestCreeper_SaveCookies_login(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := authServer(func(r *http.Request) bool {
+		c, err := r.Cookie("session")
+		return err == nil && c.Value == "s3cr3t"
+	})
+	defer ts.Close()
+
+	host := strings.Split(strings.TrimPrefix(ts.URL, "http://"), ":")[0]
+	saveState(dir, &crawlState{
+		BaseURL:  ts.URL,
+		Depth:    int8(5),
+		Frontier: []string{ts.URL},
+		Queued:   []string{ts.URL},
+		Cookies: []savedCookie{
+			{SiteCookie: SiteCookie{Name: "session", Domain: host, Path: "/"}, Value: "stale", HostOnly: true},
+		},
+	})
+
+	cc := &Creeper{
+		Depth:         int8(5),
+		Deterministic: true,
+		StateDir:      dir,
+		Resume:        true,
+		Auth:          &Auth{LoginURL: ts.URL + "/login", LoginForm: "user=me&password=secret"},
+	}
+	cc.Logger, _ = NewLogger(ioutil.Discard, "error", FormatText)
+	if err := cc.setup(); err != nil {
+		t.Fatal(err)
+	}
+	cc.crawl(cc.fetch())
+
+	if len(cc.seenLinks) != 6 {
+		t.Errorf("TestCreeper_SaveCookies_login crawled %d pages, want 6 with the session of the login", len(cc.seenLinks))
+	}
+}
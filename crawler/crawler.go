@@ -3,12 +3,15 @@ package crawler
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
+	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"path"
 	"regexp"
 	"strings"
 	"sync"
@@ -19,33 +22,156 @@ import (
 var (
 	ErrNoUrlProvided      = errors.New("No URL provided")
 	ErrIncorrectUrlFormat = errors.New("Wrong URL format provided")
+	ErrSeedNotOnSite      = errors.New("Seed is not a page of the crawled site")
+	ErrIncorrectScope     = errors.New("Scope is not a path")
 )
 
 // Crawly interface must be satisfied to do the crawling
 type Crawler interface {
 	Run() error
-	extractLinks(body string) []string
-	process(int8, string, func(string) (string, error))
+	Crawl() error
+	extractLinks(pageURL, body string) []string
+	crawl(func(string) (string, error))
+	process(int8, string, func(string) (string, error)) *page
 	display(int8, string)
 }
 
 type Creeper struct {
 	BaseURL string
 	Depth   int8
-
+	// Deterministic fetches pages of a depth level one by one, in link
+	// order, and leaves timings out of the output, so that crawls of
+	// the same content produce identical results
+	Deterministic bool
+	// Out is where the sitemap is written. Default is os.Stdout
+	Out io.Writer
+	// MaxDuration, MaxPages and MaxBytes limit the crawl. No new pages
+	// are fetched once any of them is reached. Zero means no limit
+	MaxDuration time.Duration
+	MaxPages    int
+	MaxBytes    int64
+	// Retries is how many times a page request failing without a
+	// response, with 429 or a 5xx status is retried, after RetryBackoff
+	// (default 500ms), doubled for every further retry
+	Retries      int
+	RetryBackoff time.Duration
+	// EndReason says why the crawl ended
+	EndReason string
+	// Analysis displays the link analysis after the sitemap, listing
+	// AnalysisTop most linked pages and pages with more than
+	// AnalysisMaxLinks links. Defaults are 10 and 20
+	Analysis         bool
+	AnalysisTop      int
+	AnalysisMaxLinks int
+	// Ranking displays pages ranked by PageRank, computed with Damping
+	// (default 0.85) over RankIterations (default 50). HITS adds hub
+	// and authority scores
+	Ranking        bool
+	Damping        float64
+	RankIterations int
+	HITS           bool
+	// Duplicates displays pages with identical visible text and
+	// groups of pages at least Similarity (default 0.9) similar
+	Duplicates bool
+	Similarity float64
+	// SEO records titles, descriptions, h1s, canonical urls, languages
+	// and hreflang links of the pages and displays their audit
+	SEO bool
+	// Assets collects images, scripts, stylesheets, icons, frames,
+	// media and css url() references of the pages and checks them
+	Assets bool
+	// LinkContext records anchor texts, titles, rel values and page
+	// regions of the links and reports generic and empty anchor texts
+	LinkContext bool
+	// Fragments records anchors defined by the pages and checks
+	// that links to fragments point at existing anchors
+	Fragments bool
+	// ShowProgress displays pages fetched, queued and failed, bytes,
+	// requests per second, the depth and the ETA against the budgets.
+	// The progress line is drawn on ProgressOut (default stderr), so that
+	// it does not mix with the output. When ProgressOut is not a terminal,
+	// the progress is logged every ProgressInterval (default 10s)
+	ShowProgress     bool
+	ProgressOut      io.Writer
+	ProgressInterval time.Duration
+	// Hooks are called on requests, responses, pages, errors and
+	// skipped urls, for custom processing of the crawl
+	Hooks Hooks
+	// Metrics, if set, collects metrics of the requests and the frontier
+	Metrics *Metrics
+	// Logger logs the crawl, the default slog logger if not set
+	Logger *slog.Logger
+	// Format of the output, text (default) or json
+	Format string
+	// PreviousStateDir holds the state of a previous crawl. Its pages
+	// are fetched conditionally and reused when they did not change
+	PreviousStateDir string
+	// StateDir is where the crawl state is checkpointed, every
+	// CheckpointInterval (default 30s) and after each depth level.
+	// If Resume is set, the crawl continues from the saved state
+	StateDir           string
+	CheckpointInterval time.Duration
+	Resume             bool
+	// Auth holds credentials for crawling pages behind authentication
+	Auth *Auth
+	// Requests of a crawl share a cookie jar. CookieReport reports
+	// the cookies set by the site and SaveCookies keeps them, with
+	// their values, in the crawl state, for a resumed crawl
+	CookieReport bool
+	SaveCookies  bool
+	// Source, a local directory, .zip or .tar.gz archive of a static
+	// site, like a build output, is crawled as the site of BaseURL.
+	// Requests to other hosts still go to the network
+	Source string
+	// Seeds are further pages of the site the crawl starts from, at
+	// depth 0 alongside the base URL. Scope lists the path prefixes of
+	// the pages to crawl, e.g. /docs/; links outside them are not
+	// followed (default all pages)
+	Seeds []string
+	Scope []string
+
+	client        *http.Client
 	pageScanner   *regexp.Regexp
 	baseURLParsed *url.URL
-	seen          chan *page
 	fail          chan error
 	sig           chan os.Signal
 	done          chan struct{}
 	seenLinks     map[string][]string
-	wg            sync.WaitGroup
+	seenDepths    map[string]int8
+	level         int8
+	frontier      []string
+	queued        map[string]struct{}
+	statuses      map[string]int
+	validators    map[string]validator
+	fingerprints  map[string]fingerprint
+	hashes        map[string]string
+	metadata      map[string]PageMeta
+	assets        map[string][]Asset
+	assetStatuses map[string]int
+	cssRefs       map[string][]string
+	assetChecks   map[string]chan struct{}
+	processed     int
+	events        chan struct{}
+	linkContexts  map[string][]LinkContext
+	anchors       map[string][]string
+	fragmentLinks map[string][]string
+	changes       map[string]string
+	cookies       map[string]savedCookie
+	previous      *crawlState
 	muSeen        sync.Mutex
+	started       time.Time
+	finished      time.Time
+	elapsedBefore time.Duration
+	pagesFetched  int
+	pagesFailed   int
+	bytesFetched  int64
+	muBudget      sync.Mutex
+	muOutput      sync.Mutex
 }
 
 type page struct {
 	url   string
+	depth int8
 	links []string
 }
 
@@ -53,55 +179,118 @@ func (cc *Creeper) Run() error {
 	start := time.Now()
 	var elapsed time.Duration
 
-	if err := inputCheck(cc); err != nil {
+	if err := cc.setup(); err != nil {
 		return err
 	}
-	crawlerInit(cc)
+	cc.sig = make(chan os.Signal, 1)
+	signal.Notify(cc.sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(cc.sig)
 
-	fmt.Println("\n--- Starting to crawl ---\n")
+	if cc.Format != FormatJSON {
+		fmt.Fprint(cc.Out, "\n--- Starting to crawl ---\n\n")
+	}
 
-	// launch gouroutine to collect results, catch errors and trigger
-	// sitemap display
+	stopProgress := func() {}
+	if cc.ShowProgress {
+		stopProgress = cc.reportProgress()
+	}
+
+	// launch gouroutine to catch errors and interrupts
 	go func() {
 		for {
 			select {
-			case page := <-cc.seen:
-				cc.muSeen.Lock()
-				cc.seenLinks[page.url] = page.links
-				cc.muSeen.Unlock()
 			case <-cc.done:
-				elapsed = time.Since(start)
-				break
+				return
 			case err := <-cc.fail:
-				fmt.Printf("\nfailure!: %v\n\n", err)
+				cc.logger().Error("crawl failed", "error", err)
 				os.Exit(1)
 			case sig := <-cc.sig:
 				elapsed = time.Since(start)
-				cc.display(cc.Depth, "   ")
-				fmt.Printf("\nInterrupted by %v after %s\n\n", sig, elapsed)
+				stopProgress()
+				cc.stop(EndInterrupted)
+				cc.checkpoint()
+				cc.output(elapsed)
+				if cc.Format != FormatJSON {
+					fmt.Fprintf(cc.Out, "\nInterrupted by %v after %s\n\n", sig, elapsed)
+				}
 				os.Exit(1)
 			}
 		}
 	}()
 
 	// start processing the base URL
-	//		concurrent processing of links
-	cc.wg.Add(1)
+	//		breadth-first, concurrent processing of links
+	cc.crawl(cc.fetch())
+	stopProgress()
+	elapsed = time.Since(start)
+	cc.done <- struct{}{}
+
+	return cc.output(elapsed)
+}
+
+// Crawl crawls the site without displaying the sitemap,
+// for use of the collected pages by other tools
+func (cc *Creeper) Crawl() error {
+	if err := cc.setup(); err != nil {
+		return err
+	}
+
 	go func() {
-		defer cc.wg.Done()
-		fetch := fetch()
-		cc.process(0, cc.BaseURL, fetch)
+		for {
+			select {
+			case <-cc.done:
+				return
+			case err := <-cc.fail:
+				cc.logger().Error("crawl failed", "error", err)
+			}
+		}
 	}()
 
-	cc.wg.Wait()
+	cc.crawl(cc.fetch())
 	cc.done <- struct{}{}
 
-	cc.display(cc.Depth, "   ")
-	fmt.Printf(">> The crawler took %s <<\n\n", elapsed)
+	return nil
+}
+
+// setup checks user input and prepares the crawler,
+// loading saved crawl states if requested
+func (cc *Creeper) setup() error {
+	var st *crawlState
+	if cc.Resume {
+		var err error
+		if st, err = loadState(cc.StateDir); err != nil {
+			return err
+		}
+		cc.BaseURL = st.BaseURL
+	}
+
+	if err := inputCheck(cc); err != nil {
+		return err
+	}
+	crawlerInit(cc)
+	if st != nil {
+		cc.restoreState(st)
+	}
+	if err := cc.clientSetup(); err != nil {
+		return err
+	}
+	if cc.PreviousStateDir != "" {
+		prev, err := loadState(cc.PreviousStateDir)
+		if err != nil {
+			return err
+		}
+		cc.previous = prev
+	}
 
 	return nil
 }
 
+// Validate checks the setup of the crawler, as Crawl does, so that
+// invalid input can be reported before the crawl is started
+func (cc *Creeper) Validate() error {
+	return inputCheck(cc)
+}
+
 // inputCheck checks user setup
 func inputCheck(cc *Creeper) error {
 	if cc.BaseURL == "" {
@@ -121,32 +310,95 @@ func inputCheck(cc *Creeper) error {
 	}
 	cc.baseURLParsed = baseURLParsed
 
+	if err := checkFormat(cc.Format); err != nil {
+		return err
+	}
+	if err := seedsCheck(cc); err != nil {
+		return err
+	}
+
 	if cc.Depth > int8(10) {
-		fmt.Printf("Up to 10 levels of crawling are allowed. Capping at 10.\n\n")
+		cc.logger().Warn("up to 10 levels of crawling are allowed, capping at 10", "depth", cc.Depth)
 		cc.Depth = int8(10)
 	}
 
 	return err
 }
 
-func checkURL(url string) error {
-	regStr := fmt.Sprint(`^http(s)?://[a-zA-Z0-9\-_.]+/?$`)
-	regex := regexp.MustCompile(regStr)
-	if regex.MatchString(url) {
-		return nil
+// checkURL checks that the base url is the origin of a site,
+// http(s)://host[:port], optionally followed by a slash
+func checkURL(s string) error {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return ErrIncorrectUrlFormat
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Hostname() == "" || u.User != nil ||
+		u.Path != "" && u.Path != "/" || u.RawQuery != "" {
+		return ErrIncorrectUrlFormat
+	}
+	return nil
+}
+
+// seedsCheck checks that the seeds are pages of the site, given by
+// absolute urls, and that the scope is made of paths
+func seedsCheck(cc *Creeper) error {
+	for i, s := range cc.Seeds {
+		u, err := url.Parse(strings.TrimSpace(s))
+		if err != nil || u.Scheme != cc.baseURLParsed.Scheme || u.Host != cc.baseURLParsed.Host {
+			return fmt.Errorf("%v: %s", ErrSeedNotOnSite, s)
+		}
+		u.Fragment = ""
+		cc.Seeds[i] = u.String()
+		if u.Path == "/" && u.RawQuery == "" {
+			cc.Seeds[i] = cc.BaseURL
+		}
+	}
+	for _, p := range cc.Scope {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("%v: %s", ErrIncorrectScope, p)
+		}
 	}
-	return ErrIncorrectUrlFormat
+	return nil
 }
 
 func crawlerInit(cc *Creeper) {
 	pageScannerSetup(cc)
+	if cc.Out == nil {
+		cc.Out = os.Stdout
+	}
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+	cc.muBudget.Lock()
+	defer cc.muBudget.Unlock()
+
 	cc.seenLinks = make(map[string][]string)
-	cc.seen = make(chan *page)
+	cc.seenDepths = make(map[string]int8)
+	cc.level = 0
+	cc.frontier = nil
+	cc.queued = nil
+	cc.processed = 0
+	cc.events = make(chan struct{}, 1)
+	cc.statuses = make(map[string]int)
+	cc.validators = make(map[string]validator)
+	cc.fingerprints = make(map[string]fingerprint)
+	cc.hashes = make(map[string]string)
+	cc.metadata = make(map[string]PageMeta)
+	cc.assets = make(map[string][]Asset)
+	cc.assetStatuses = make(map[string]int)
+	cc.cssRefs = make(map[string][]string)
+	cc.assetChecks = make(map[string]chan struct{})
+	cc.linkContexts = make(map[string][]LinkContext)
+	cc.anchors = make(map[string][]string)
+	cc.fragmentLinks = make(map[string][]string)
+	cc.changes = make(map[string]string)
+	cc.cookies = make(map[string]savedCookie)
+	cc.previous = nil
+	cc.elapsedBefore = 0
+	cc.pagesFetched = 0
+	cc.pagesFailed = 0
+	cc.bytesFetched = 0
 	cc.fail = make(chan error)
 	cc.done = make(chan struct{})
-
-	cc.sig = make(chan os.Signal, 1)
-	signal.Notify(cc.sig, syscall.SIGINT, syscall.SIGTERM)
 }
 
 func pageScannerSetup(cc *Creeper) {
@@ -156,101 +408,320 @@ func pageScannerSetup(cc *Creeper) {
 }
 
 func regexSetup(s string) *regexp.Regexp {
-	regStr := fmt.Sprintf(`<a\s+(?:[a-zA-Z0-9_="\- ]+)?href="((?:%s)?/[a-zA-Z_0-9\-/&?]+)"\s*([a-z=]*)?(\s*/?>)?`, s)
+	regStr := fmt.Sprintf(`<a\s+(?:[a-zA-Z0-9_="\- ]+)?href="((?:%s)?[^"#:\s]+)(?:#[^"]*)?"\s*([a-z=]*)?(\s*/?>)?`, regexp.QuoteMeta(s))
 	return regexp.MustCompile(regStr)
 }
 
-// process processes a page at a given url
-// to find links for the given criteria
-func (cc *Creeper) process(depth int8, url string, fetch func(string) (string, error)) {
-	if depth > cc.Depth {
-		return
-	}
-	if url == "" {
-		cc.fail <- errors.New("incorrect input to process")
-		return
-	}
+// crawl walks the site breadth-first, one depth level at a time.
+// Pages of a level are fetched concurrently (sequentially in
+// deterministic mode), but the next frontier is built in page and
+// link order, so every page is recorded at its shortest click
+// distance from the base URL.
+// Crawling stops early when a budget is exhausted; pages already
+// being fetched are still recorded.
+func (cc *Creeper) crawl(fetch func(string) (string, error)) {
+	cc.budgetStart()
+	defer cc.budgetEnd()
 
 	cc.muSeen.Lock()
-	if _, ok := cc.seenLinks[url]; ok {
-		cc.muSeen.Unlock()
-		return
+	if cc.queued == nil {
+		cc.frontier = []string{cc.BaseURL}
+		cc.queued = map[string]struct{}{cc.BaseURL: struct{}{}}
+		for _, s := range cc.Seeds {
+			if _, ok := cc.queued[s]; ok {
+				continue
+			}
+			cc.queued[s] = struct{}{}
+			cc.frontier = append(cc.frontier, s)
+		}
 	}
 	cc.muSeen.Unlock()
 
-	body, err := fetch(url)
-	if err != nil {
-		log.Printf("Error while fetching url: [%s]\n", url)
-		return
-	}
-	links := cc.extractLinks(body)
+	stop := cc.checkpointStart()
+	defer stop()
 
-	cc.seen <- &page{
-		url:   url,
-		links: links,
-	}
+	for !cc.budgetExhausted() {
+		cc.muSeen.Lock()
+		depth, frontier := cc.level, cc.frontier
+		cc.muSeen.Unlock()
+		if depth > cc.Depth || len(frontier) == 0 {
+			for _, u := range frontier {
+				cc.onSkip(u, SkipDepth)
+			}
+			break
+		}
+
+		pages := make([]*page, len(frontier))
+		cc.Metrics.addFrontier(len(frontier))
+
+		var wg sync.WaitGroup
+		for i, u := range frontier {
+			if cc.Deterministic {
+				pages[i] = cc.visit(depth, u, fetch)
+				continue
+			}
+			wg.Add(1)
+			go func(i int, u string) {
+				defer wg.Done()
+				pages[i] = cc.visit(depth, u, fetch)
+			}(i, u)
+		}
+		wg.Wait()
+
+		// keep the unfinished level as the frontier, so that
+		// a resumed crawl picks up the remaining pages
+		if cc.budgetExhausted() {
+			break
+		}
 
-	depth = depth + 1
-	for _, link := range links {
 		cc.muSeen.Lock()
-		if _, ok := cc.seenLinks[link]; ok {
-			cc.muSeen.Unlock()
-			continue
+		next := []string{}
+		for _, p := range pages {
+			if p == nil {
+				continue
+			}
+			for _, link := range p.links {
+				if _, ok := cc.queued[link]; ok {
+					continue
+				}
+				cc.queued[link] = struct{}{}
+				next = append(next, link)
+			}
 		}
+		cc.frontier = next
+		cc.level++
 		cc.muSeen.Unlock()
+		cc.notify()
 
-		if url == link {
-			continue
+		cc.checkpoint()
+	}
+}
+
+// visit records the page at a given url, unless it was
+// already processed before the crawl was resumed
+func (cc *Creeper) visit(depth int8, url string, fetch func(string) (string, error)) *page {
+	cc.muSeen.Lock()
+	links, ok := cc.seenLinks[url]
+	if ok {
+		cc.processed++
+	}
+	cc.muSeen.Unlock()
+	if ok {
+		cc.Metrics.addFrontier(-1)
+		return &page{
+			url:   url,
+			depth: depth,
+			links: links,
 		}
+	}
+
+	p := cc.process(depth, url, fetch)
+	cc.Metrics.addFrontier(-1)
+
+	cc.muSeen.Lock()
+	cc.processed++
+	if p != nil {
+		cc.seenLinks[p.url] = p.links
+		cc.seenDepths[p.url] = p.depth
+	}
+	cc.muSeen.Unlock()
+	cc.notify()
+
+	return p
+}
 
-		cc.wg.Add(1)
-		go func(l string) {
-			defer cc.wg.Done()
-			cc.process(depth, l, fetch)
-		}(link)
+// process fetches a page at a given url
+// and finds its links for the given criteria
+func (cc *Creeper) process(depth int8, url string, fetch func(string) (string, error)) *page {
+	if depth > cc.Depth {
+		return nil
+	}
+	if url == "" {
+		cc.fail <- errors.New("incorrect input to process")
+		return nil
+	}
+
+	if !cc.budgetReserve() {
+		cc.onSkip(url, SkipBudget)
+		return nil
+	}
+	start := time.Now()
+	body, err := fetch(url)
+	if err == ErrNotModified {
+		cc.logger().Debug("page not modified", "url", url, "depth", depth, "duration", time.Since(start))
+		return cc.unchangedPage(depth, url)
+	}
+	if err == ErrRequestVetoed {
+		cc.budgetRelease()
+		cc.onSkip(url, SkipVetoed)
+		return nil
+	}
+	if err != nil {
+		cc.budgetFail()
+		cc.onError(url, err)
+		cc.logger().Warn("page not fetched", "url", url, "depth", depth, "status", cc.status(url), "duration", time.Since(start), "error", err)
+		return nil
+	}
+	cc.budgetSpend(len(body))
+	cc.recordFingerprint(url, body)
+	cc.recordMeta(url, body)
+	cc.recordAssets(url, body)
+	cc.recordFragments(url, body)
+
+	links := cc.extractLinks(url, body)
+	cc.recordChange(url, body, links)
+	cc.recordLinkContexts(url, body, links)
+	cc.logger().Debug("page fetched", "url", url, "depth", depth, "status", cc.status(url), "duration", time.Since(start), "bytes", len(body), "links", len(links))
+	cc.onPage(Page{
+		URL:   url,
+		Depth: depth,
+		Body:  body,
+		Links: links,
+	})
+
+	return &page{
+		url:   url,
+		depth: depth,
+		links: links,
 	}
 }
 
 // fetch retrieves content at the given URL
-func fetch() func(string) (string, error) {
+// - pages known from the previous crawl are requested conditionally
+// - status codes, ETag and Last-Modified of the responses are recorded
+// - the OnRequest and OnResponse hooks are called
+// - metrics of the requests are collected
+func (cc *Creeper) fetch() func(string) (string, error) {
 	return func(url string) (string, error) {
-		res, err := http.Get(url)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
 		if err != nil {
 			return "", err
 		}
+		if v, ok := cc.previousValidator(url); ok {
+			if v.ETag != "" {
+				req.Header.Set("If-None-Match", v.ETag)
+			}
+			if v.LastModified != "" {
+				req.Header.Set("If-Modified-Since", v.LastModified)
+			}
+		}
+		if !cc.onRequest(req) {
+			return "", ErrRequestVetoed
+		}
+
+		status, size := 0, 0
+		cc.Metrics.fetchStarted()
+		defer func(start time.Time) {
+			cc.Metrics.fetchDone(req.URL.Host, status, time.Since(start), size)
+		}(time.Now())
+
+		res, err := cc.do(req)
+		if err != nil {
+			cc.recordStatus(url, 0)
+			return "", err
+		}
 		defer res.Body.Close()
+		status = res.StatusCode
+		cc.onResponse(res)
+
+		if res.StatusCode == http.StatusNotModified {
+			return "", ErrNotModified
+		}
+		cc.recordStatus(url, res.StatusCode)
+		if res.StatusCode >= http.StatusBadRequest {
+			return "", fmt.Errorf("Error retrieving content for url %s: status %d", url, res.StatusCode)
+		}
 
 		body, err := ioutil.ReadAll(res.Body)
 		if err != nil {
 			return "", fmt.Errorf("Error retrieving content for url %s: %v", url, err)
 		}
+		size = len(body)
+		cc.recordValidator(url, validator{
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+		})
 
 		return string(body), nil
 	}
 }
 
+// defaultRetryBackoff is the wait before the first retry of a page request
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// do sends a page request, retrying it Retries times if it fails
+// without a response, with 429 Too Many Requests or a server error
+func (cc *Creeper) do(req *http.Request) (*http.Response, error) {
+	backoff := cc.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for retry := 1; ; retry++ {
+		res, err := cc.httpClient().Do(req)
+		if retry > cc.Retries || !retryable(res, err) {
+			return res, err
+		}
+		status := 0
+		if err == nil {
+			status = res.StatusCode
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		cc.Metrics.retried()
+		cc.logger().Debug("page request retried", "url", req.URL.Redacted(), "retry", retry, "status", status, "backoff", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryable returns true if a request failed in a way worth retrying
+func retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+}
+
+// recordStatus stores the response status code of a url,
+// 0 when no response was received
+func (cc *Creeper) recordStatus(url string, status int) {
+	cc.muSeen.Lock()
+	cc.statuses[url] = status
+	cc.muSeen.Unlock()
+}
+
 // extractLinks returns a list of urls
+// - relative links are resolved against the page URL
+// - links leaving the site, to the base page or to files other than
+// pages (images, stylesheets, ...) are left out
 // - number of retrieved links is hardcoded to the maximum of 30
-func (cc *Creeper) extractLinks(body string) []string {
+func (cc *Creeper) extractLinks(pageURL, body string) []string {
 	links := []string{}
 	seen := map[string]struct{}{}
 
+	from, err := url.Parse(pageURL)
+	if err != nil {
+		from = cc.baseURLParsed
+	}
 	matches := cc.pageScanner.FindAllStringSubmatch(body, 30)
 	for _, m := range matches {
-		l := m[1]
-
-		u, err := url.Parse(l)
+		u, err := url.Parse(m[1])
 		if err != nil {
-			log.Printf("%v", err)
+			cc.logger().Debug("link not parsed", "link", m[1], "error", err)
 			continue
 		}
-		if strings.Contains(l, "redirect") {
+		u = from.ResolveReference(u)
+		if !cc.pageLink(u) {
 			continue
 		}
-
-		if !u.IsAbs() {
-			ru := cc.baseURLParsed.ResolveReference(u)
-			l = fmt.Sprintf("%s", ru)
+		l := u.String()
+		if !cc.inScope(u) {
+			cc.onSkip(l, SkipScope)
+			continue
+		}
+		if strings.Contains(m[1], "redirect") {
+			cc.onSkip(l, SkipRedirect)
+			continue
 		}
 		if _, ok := seen[l]; ok {
 			continue
@@ -261,14 +732,45 @@ func (cc *Creeper) extractLinks(body string) []string {
 	return links
 }
 
+// pageLink returns true if the link points to a page of the site
+// other than the base page
+func (cc *Creeper) pageLink(u *url.URL) bool {
+	if u.Scheme != cc.baseURLParsed.Scheme || u.Host != cc.baseURLParsed.Host {
+		return false
+	}
+	if p := strings.TrimSuffix(u.Path, "/"); p == strings.TrimSuffix(cc.baseURLParsed.Path, "/") && u.RawQuery == "" {
+		return false
+	}
+	ext := path.Ext(u.Path)
+	if ext == "" {
+		return true
+	}
+	typ := mime.TypeByExtension(ext)
+	return typ == "" || strings.HasPrefix(typ, "text/html") || strings.HasPrefix(typ, "application/xhtml")
+}
+
+// inScope returns true if the link is within the path prefixes
+// of the scope, or there is no scope
+func (cc *Creeper) inScope(u *url.URL) bool {
+	if len(cc.Scope) == 0 {
+		return true
+	}
+	for _, p := range cc.Scope {
+		if strings.HasPrefix(u.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // display displays the sitemap to the given depth
 func (cc *Creeper) display(depth int8, offset string) {
-	fmt.Println("👍 SiteMap display 👍\n")
+	fmt.Fprint(cc.Out, "👍 SiteMap display 👍\n\n")
 
 	displayedPages := make(map[string]struct{})
-	displayPageMap(cc.seenLinks, cc.Depth, displayedPages, offset, int8(0), cc.BaseURL)
+	displayPageMap(cc.Out, cc.seenLinks, cc.Depth, displayedPages, offset, int8(0), cc.BaseURL)
 
-	fmt.Println("\n👍 The END 👍")
+	fmt.Fprintln(cc.Out, "\n👍 The END 👍")
 }
 
 func createOffset(offset string, depth int8) string {
@@ -282,32 +784,32 @@ func createOffset(offset string, depth int8) string {
 }
 
 // displayPageMap provides recursive display for links
-func displayPageMap(seenLinks map[string][]string, maxDepth int8, displayedPages map[string]struct{}, offset string, depth int8, url string) {
+func displayPageMap(w io.Writer, seenLinks map[string][]string, maxDepth int8, displayedPages map[string]struct{}, offset string, depth int8, url string) {
 	urlOfs := createOffset(offset, depth)
 	linkOfs := fmt.Sprintf("%s%s", urlOfs, offset)
 
 	links := seenLinks[url]
 
-	fmt.Println("================================")
-	fmt.Printf("%s* %s (depth %d)\n", urlOfs, url, depth)
-	fmt.Printf("%s number of links = %d\n", linkOfs, len(links))
-	fmt.Println("--------------------------------")
+	fmt.Fprintln(w, "================================")
+	fmt.Fprintf(w, "%s* %s (depth %d)\n", urlOfs, url, depth)
+	fmt.Fprintf(w, "%s number of links = %d\n", linkOfs, len(links))
+	fmt.Fprintln(w, "--------------------------------")
 
 	depth = depth + 1
 	if depth > maxDepth {
 		return
 	}
 	for i, l := range links {
-		fmt.Printf("%s- %d - [%s]\n", linkOfs, i, l)
+		fmt.Fprintf(w, "%s- %d - [%s]\n", linkOfs, i, l)
 		if url == l {
 			continue
 		}
 		if _, ok := displayedPages[url]; ok {
-			fmt.Printf("%s (links displayed before)\n", linkOfs)
+			fmt.Fprintf(w, "%s (links displayed before)\n", linkOfs)
 			continue
 		}
-		displayPageMap(seenLinks, maxDepth, displayedPages, offset, depth, l)
+		displayPageMap(w, seenLinks, maxDepth, displayedPages, offset, depth, l)
 	}
 	displayedPages[url] = struct{}{}
-	fmt.Println("--------------------------------")
+	fmt.Fprintln(w, "--------------------------------")
 }
@@ -1,11 +1,11 @@
 package crawler
 
 import (
-	"fmt"
+	"bytes"
 	"net/url"
 	"reflect"
 	"regexp"
-	"sync"
+	"strings"
 	"testing"
 )
 
@@ -20,7 +20,8 @@ func TestCreeper_extractLinks(t *testing.T) {
 		baseURLParsed *url.URL
 	}
 	type args struct {
-		body string
+		pageURL string
+		body    string
 	}
 	tests := []struct {
 		name   string
@@ -37,6 +38,7 @@ func TestCreeper_extractLinks(t *testing.T) {
 				baseURLParsed: testBaseURLParsed,
 			},
 			args: args{
+				pageURL: testBaseURL,
 				body: `<p><br /> We’ve had a fantastic time catching up with the Mmmmm community in <a href="https://mmmmm.com/t/mmmm-hitting-meetup/1111/22">Edinburgh, Bristol and Manchester</a> over the last few months. These meetups have cemented our commitment to keep spreading <a href="https://mmmmm.com/static/info">community forum</a> the word across the UK this year.</p>
 
 				<p><a href="https://notthisone.com/about">community forum</a></p>
@@ -63,6 +65,7 @@ func TestCreeper_extractLinks(t *testing.T) {
 				baseURLParsed: testBaseURLParsed,
 			},
 			args: args{
+				pageURL: testBaseURL,
 				body: `<p><br /> We’ve had a fantastic time catching up with the Mmmmm community in <a href="https://mmmmm.com/t/mmmm-hitting-meetup/1111/22">Edinburgh, Bristol and Manchester</a> over the last few months. These meetups have cemented our commitment to keep spreading <a href="http://mmmmm.com/static/info">community forum</a> the word across the UK this year.</p>
 
 				<p><a href="https://notthisone.com/about">community forum</a></p>
@@ -86,6 +89,7 @@ func TestCreeper_extractLinks(t *testing.T) {
 				baseURLParsed: testBaseURLParsed,
 			},
 			args: args{
+				pageURL: testBaseURL,
 				body: `<p><br /> We’ve had a fantastic time catching up with the Mmmmm community in <a href="https://mmmmm.com/t/mmmm-hitting-meetup/1111/22">Edinburgh, Bristol and Manchester</a> over the last few months. These meetups have cemented our commitment to keep spreading <a href="http://mmmmm.com/static/info">community forum</a> the word across the UK this year.</p>
 
 				<p><a href="https://notthisone.com/about">community forum</a></p>
@@ -111,6 +115,7 @@ func TestCreeper_extractLinks(t *testing.T) {
 				baseURLParsed: testBaseURLParsed,
 			},
 			args: args{
+				pageURL: testBaseURL,
 				body: `<p><br /> We’ve had a fantastic time catching up with the Mmmmm community in <a href="https://mmmmm.com/t/mmmm-hitting-meetup/1111/22">Edinburgh, Bristol and Manchester</a> over the last few months. These meetups have cemented our commitment to keep spreading <a href="http://mmmmm.com/static/info">community forum</a> the word across the UK this year.</p>
 
 				<p><a href="https://notthisone.com/about">community forum</a></p>
@@ -125,6 +130,24 @@ func TestCreeper_extractLinks(t *testing.T) {
 				"https://mmmmm.com/t/about",
 			},
 		},
+		{
+			// test 5
+			name: "web page contains links to fragments",
+			fields: fields{
+				BaseURL:       testBaseURL,
+				pageScanner:   testPageScanner,
+				baseURLParsed: testBaseURLParsed,
+			},
+			args: args{
+				pageURL: testBaseURL,
+				body: `<p>Read the <a href="/guide#install">installation guide</a> and the <a href="https://mmmmm.com/guide#usage">usage</a>, or go <a href="#top">back to top</a>.</p>
+				<p>Questions? See the <a href="/faq">FAQ</a>.</p>`,
+			},
+			want: []string{
+				"https://mmmmm.com/guide",
+				"https://mmmmm.com/faq",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -133,7 +156,7 @@ func TestCreeper_extractLinks(t *testing.T) {
 				pageScanner:   tt.fields.pageScanner,
 				baseURLParsed: tt.fields.baseURLParsed,
 			}
-			got := cc.extractLinks(tt.args.body)
+			got := cc.extractLinks(tt.args.pageURL, tt.args.body)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("TestCreeper.extractLinks() = %v, want %v", got, tt.want)
 			}
@@ -242,6 +265,37 @@ func TestCreeper_inputCheck(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		// test 5
+		{
+			name: "User input: url with a port",
+			fields: fields{
+				BaseURL: "http://localhost:4000/",
+				Depth:   int8(3),
+			},
+			want: &Creeper{
+				BaseURL: "http://localhost:4000",
+				Depth:   3,
+			},
+			wantErr: false,
+		},
+		// test 6
+		{
+			name: "Incorrect user input: url with a path",
+			fields: fields{
+				BaseURL: "https://aaa.com/docs",
+				Depth:   int8(3),
+			},
+			wantErr: true,
+		},
+		// test 7
+		{
+			name: "Incorrect user input: invalid port",
+			fields: fields{
+				BaseURL: "http://localhost:port",
+				Depth:   int8(3),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -262,32 +316,40 @@ func TestCreeper_inputCheck(t *testing.T) {
 	}
 }
 
-func TestCreeper_process(t *testing.T) {
+func TestCreeper_Crawl_port(t *testing.T) {
+	ts := mockServer(nil)
+	defer ts.Close()
+
+	cc := &Creeper{
+		BaseURL:       ts.URL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	if err := cc.Crawl(); err != nil {
+		t.Fatalf("TestCreeper_Crawl_port error = %v", err)
+	}
+	if len(cc.seenLinks) != 6 {
+		t.Errorf("TestCreeper_Crawl_port crawled %d pages of %s, want 6", len(cc.seenLinks), ts.URL)
+	}
+}
+
+func TestCreeper_crawl(t *testing.T) {
 	type fields struct {
-		BaseURL       string
-		pageScanner   *regexp.Regexp
-		Depth         int8
-		baseURLParsed *url.URL
-		seen          chan *page
-		fail          chan error
-		done          chan struct{}
-		seenLinks     map[string][]string
-		wg            sync.WaitGroup
-		muSeen        sync.Mutex
+		BaseURL string
+		Depth   int8
 	}
 	type args struct {
-		depth int8
-		url   string
 		fetch func(string) (string, error)
 	}
 	fetch := mockFetch(testBaseURL)
 	basePage := "https://mmmmm.com"
 
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   map[string][]string
+		name       string
+		fields     fields
+		args       args
+		want       map[string][]string
+		wantDepths map[string]int8
 	}{
 		// test 1
 		{
@@ -297,8 +359,6 @@ func TestCreeper_process(t *testing.T) {
 				Depth:   int8(1),
 			},
 			args: args{
-				depth: int8(1),
-				url:   basePage,
 				fetch: fetch,
 			},
 			want: map[string][]string{
@@ -306,6 +366,11 @@ func TestCreeper_process(t *testing.T) {
 				"https://mmmmm.com/faq":   []string{"https://mmmmm.com/about", "https://mmmmm.com/info"},
 				"https://mmmmm.com/about": []string{"https://mmmmm.com/careers", "https://mmmmm.com/faq"},
 			},
+			wantDepths: map[string]int8{
+				"https://mmmmm.com":       0,
+				"https://mmmmm.com/faq":   1,
+				"https://mmmmm.com/about": 1,
+			},
 		},
 		// test 2
 		{
@@ -315,8 +380,6 @@ func TestCreeper_process(t *testing.T) {
 				Depth:   int8(2),
 			},
 			args: args{
-				depth: int8(1),
-				url:   basePage,
 				fetch: fetch,
 			},
 			want: map[string][]string{
@@ -326,6 +389,13 @@ func TestCreeper_process(t *testing.T) {
 				"https://mmmmm.com/info":    []string{"https://mmmmm.com/about", "https://mmmmm.com/generic"},
 				"https://mmmmm.com/careers": []string{"https://mmmmm.com/generic"},
 			},
+			wantDepths: map[string]int8{
+				"https://mmmmm.com":         0,
+				"https://mmmmm.com/faq":     1,
+				"https://mmmmm.com/about":   1,
+				"https://mmmmm.com/info":    2,
+				"https://mmmmm.com/careers": 2,
+			},
 		},
 		// test 3
 		{
@@ -335,8 +405,6 @@ func TestCreeper_process(t *testing.T) {
 				Depth:   int8(8),
 			},
 			args: args{
-				depth: int8(8),
-				url:   basePage,
 				fetch: fetch,
 			},
 			want: map[string][]string{
@@ -347,8 +415,16 @@ func TestCreeper_process(t *testing.T) {
 				"https://mmmmm.com/careers": []string{"https://mmmmm.com/generic"},
 				"https://mmmmm.com/generic": []string{},
 			},
+			wantDepths: map[string]int8{
+				"https://mmmmm.com":         0,
+				"https://mmmmm.com/faq":     1,
+				"https://mmmmm.com/about":   1,
+				"https://mmmmm.com/info":    2,
+				"https://mmmmm.com/careers": 2,
+				"https://mmmmm.com/generic": 3,
+			},
 		},
-		// test 5
+		// test 4
 		{
 			name: "processing to the depth of 0",
 			fields: fields{
@@ -356,61 +432,173 @@ func TestCreeper_process(t *testing.T) {
 				Depth:   int8(0),
 			},
 			args: args{
-				depth: int8(0),
-				url:   basePage,
 				fetch: fetch,
 			},
 			want: map[string][]string{
 				"https://mmmmm.com": []string{"https://mmmmm.com/faq", "https://mmmmm.com/about"},
 			},
+			wantDepths: map[string]int8{
+				"https://mmmmm.com": 0,
+			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cc := &Creeper{
-				BaseURL:       tt.fields.BaseURL,
-				Depth:         tt.fields.Depth,
-				baseURLParsed: tt.fields.baseURLParsed,
-				seen:          tt.fields.seen,
-				fail:          tt.fields.fail,
-				done:          tt.fields.done,
-				seenLinks:     tt.fields.seenLinks,
+				BaseURL: tt.fields.BaseURL,
+				Depth:   tt.fields.Depth,
 			}
 			inputCheck(cc)
 			crawlerInit(cc)
 
-			go func() {
-				for {
-					select {
-					case page := <-cc.seen:
-						cc.muSeen.Lock()
-						cc.seenLinks[page.url] = page.links
-						cc.muSeen.Unlock()
-					case <-cc.done:
-						break
-					case err := <-cc.fail:
-						fmt.Printf("\nfailure!: %v\n\n", err)
-						// if !tt.wantErr {
-						// 	t.Errorf("TestCreeper_process error = %v, wantErr %v", err, tt.wantErr)
-						// }
-						break
-					default:
-					}
-				}
-			}()
+			cc.crawl(tt.args.fetch)
 
-			cc.wg.Add(1)
-			go func() {
-				defer cc.wg.Done()
-				cc.process(0, tt.args.url, tt.args.fetch)
-			}()
-			cc.wg.Wait()
-			cc.done <- struct{}{}
+			if !reflect.DeepEqual(cc.seenLinks, tt.want) {
+				t.Errorf("TestCreeper_crawl = %+v, want %+v", cc.seenLinks, tt.want)
+			}
+			if !reflect.DeepEqual(cc.seenDepths, tt.wantDepths) {
+				t.Errorf("TestCreeper_crawl depths = %+v, want %+v", cc.seenDepths, tt.wantDepths)
+			}
+		})
+	}
+}
 
+func TestCreeper_crawl_seeds(t *testing.T) {
+	tests := []struct {
+		name    string
+		depth   int8
+		seeds   []string
+		scope   []string
+		want    map[string][]string
+		wantErr error
+	}{
+		{
+			name:  "seeds at depth 0",
+			depth: 0,
+			seeds: []string{"https://mmmmm.com/info#team", "https://mmmmm.com/"},
+			want: map[string][]string{
+				"https://mmmmm.com":      []string{"https://mmmmm.com/faq", "https://mmmmm.com/about"},
+				"https://mmmmm.com/info": []string{"https://mmmmm.com/about", "https://mmmmm.com/generic"},
+			},
+		},
+		{
+			name:  "scope",
+			depth: 5,
+			scope: []string{"/about", "/careers"},
+			want: map[string][]string{
+				"https://mmmmm.com":         []string{"https://mmmmm.com/about"},
+				"https://mmmmm.com/about":   []string{"https://mmmmm.com/careers"},
+				"https://mmmmm.com/careers": []string{},
+			},
+		},
+		{
+			name:  "seed outside the scope",
+			depth: 1,
+			seeds: []string{"https://mmmmm.com/generic"},
+			scope: []string{"/info"},
+			want: map[string][]string{
+				"https://mmmmm.com":         []string{},
+				"https://mmmmm.com/generic": []string{},
+			},
+		},
+		{
+			name:    "seed on another site",
+			seeds:   []string{"https://notthisone.com/about"},
+			wantErr: ErrSeedNotOnSite,
+		},
+		{
+			name:    "relative seed",
+			seeds:   []string{"/about"},
+			wantErr: ErrSeedNotOnSite,
+		},
+		{
+			name:    "scope without a slash",
+			scope:   []string{"docs"},
+			wantErr: ErrIncorrectScope,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := newTestCreeper(testBaseURL)
+			cc.Depth = tt.depth
+			cc.Seeds = tt.seeds
+			cc.Scope = tt.scope
+			err := cc.Validate()
+			if tt.wantErr != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Errorf("TestCreeper_crawl_seeds error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TestCreeper_crawl_seeds error = %v", err)
+			}
+			cc.crawl(mockFetch(testBaseURL))
 			if !reflect.DeepEqual(cc.seenLinks, tt.want) {
-				t.Errorf("TestCreeper_process = %+v, want %+v", cc.seenLinks, tt.want)
+				t.Errorf("TestCreeper_crawl_seeds = %v, want %v", cc.seenLinks, tt.want)
 			}
 		})
 	}
 }
+
+func TestCreeper_crawl_repeatable(t *testing.T) {
+	fetch := mockFetch(testBaseURL)
+
+	var firstLinks map[string][]string
+	var firstDepths map[string]int8
+
+	for i := 0; i < 20; i++ {
+		cc := &Creeper{
+			BaseURL: testBaseURL,
+			Depth:   int8(2),
+		}
+		inputCheck(cc)
+		crawlerInit(cc)
+
+		cc.crawl(fetch)
+
+		if i == 0 {
+			firstLinks = cc.seenLinks
+			firstDepths = cc.seenDepths
+			continue
+		}
+		if !reflect.DeepEqual(cc.seenLinks, firstLinks) {
+			t.Fatalf("TestCreeper_crawl_repeatable run %d = %+v, want %+v", i, cc.seenLinks, firstLinks)
+		}
+		if !reflect.DeepEqual(cc.seenDepths, firstDepths) {
+			t.Fatalf("TestCreeper_crawl_repeatable run %d depths = %+v, want %+v", i, cc.seenDepths, firstDepths)
+		}
+	}
+}
+
+func TestCreeper_display_deterministic(t *testing.T) {
+	fetch := mockFetch(testBaseURL)
+
+	var first string
+	for i := 0; i < 10; i++ {
+		out := &bytes.Buffer{}
+		cc := &Creeper{
+			BaseURL:       testBaseURL,
+			Depth:         int8(3),
+			Deterministic: true,
+			Out:           out,
+		}
+		inputCheck(cc)
+		crawlerInit(cc)
+
+		cc.crawl(fetch)
+		cc.display(cc.Depth, "   ")
+
+		if i == 0 {
+			first = out.String()
+			if !strings.Contains(first, "* https://mmmmm.com/generic (depth 3)") {
+				t.Fatalf("TestCreeper_display_deterministic output misses pages:\n%s", first)
+			}
+			continue
+		}
+		if out.String() != first {
+			t.Fatalf("TestCreeper_display_deterministic run %d =\n%s\nwant\n%s", i, out.String(), first)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package crawlertest serves the mock site the crawler tests crawl
+package crawlertest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Site lays out the pages of the mock dir as a static site,
+// with directory indexes and extensions
+var Site = map[string]string{
+	"index.html":       "basePage.html",
+	"about/index.html": "about.html",
+	"faq.html":         "faq.html",
+	"careers.html":     "careers.html",
+	"info/index.html":  "info.html",
+	"generic.html":     "generic.html",
+}
+
+// Files reads the pages of the mock site, by their path in the site
+// - it panics if a page cannot be read, failing the test
+func Files() map[string][]byte {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "mock")
+
+	files := map[string][]byte{}
+	for name, mock := range Site {
+		data, err := ioutil.ReadFile(filepath.Join(dir, mock))
+		if err != nil {
+			panic("crawlertest: " + err.Error())
+		}
+		files[name] = data
+	}
+	return files
+}
+
+// Handler serves the mock site with an ETag per page, given
+// by url path, and answers conditional requests
+func Handler(etags map[string]string) http.HandlerFunc {
+	files := Files()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := etags[r.URL.Path]
+		if etag != "" && r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		p := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		for _, name := range []string{p, path.Join(p, "index.html"), p + ".html"} {
+			body, ok := files[name]
+			if !ok {
+				continue
+			}
+			if etag != "" {
+				w.Header().Set("ETag", etag)
+			}
+			w.Write(body)
+			return
+		}
+		http.NotFound(w, r)
+	})
+}
+
+// Transport serves requests to any host with the handler,
+// without a network connection
+type Transport struct {
+	Handler http.Handler
+}
+
+func (t Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.Handler.ServeHTTP(rec, r)
+	return rec.Result(), nil
+}
@@ -0,0 +1,296 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+var (
+	ErrUnknownCrawl = errors.New("Not a saved crawl state nor a crawl JSON output")
+)
+
+// CrawlDiff holds the differences between two saved crawls
+type CrawlDiff struct {
+	Added          []string       `json:"added"`
+	Removed        []string       `json:"removed"`
+	LinksChanged   []LinksChange  `json:"links_changed"`
+	NewBrokenLinks []BrokenLink   `json:"new_broken_links"`
+	StatusChanged  []StatusChange `json:"status_changed"`
+	DepthChanged   []DepthChange  `json:"depth_changed"`
+}
+
+// LinksChange lists outgoing links of a page added or removed
+type LinksChange struct {
+	URL     string   `json:"url"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// BrokenLink is a link to a page which could not be retrieved.
+// Status is 0 when no response was received
+type BrokenLink struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status int    `json:"status"`
+}
+
+// StatusChange is a change of the response status code of a url
+type StatusChange struct {
+	URL string `json:"url"`
+	Old int    `json:"old"`
+	New int    `json:"new"`
+}
+
+// DepthChange is a change of the click depth of a page
+type DepthChange struct {
+	URL string `json:"url"`
+	Old int8   `json:"old"`
+	New int8   `json:"new"`
+}
+
+// DiffCrawls compares two saved crawls. A crawl is given as a state
+// directory, a saved state JSON file or the JSON output of a crawl.
+func DiffCrawls(oldPath, newPath string) (*CrawlDiff, error) {
+	old, err := loadCrawl(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	curr, err := loadCrawl(newPath)
+	if err != nil {
+		return nil, err
+	}
+	return diffStates(old, curr), nil
+}
+
+// loadCrawl reads a saved crawl from a state directory, a state file
+// or a file with the JSON output of a crawl
+func loadCrawl(path string) (*crawlState, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return loadState(path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	switch {
+	case fields["links"] != nil:
+		st := &crawlState{}
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return st, nil
+	case fields["pages"] != nil:
+		r := &Result{}
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return resultState(r), nil
+	}
+	return nil, fmt.Errorf("%v: %s", ErrUnknownCrawl, path)
+}
+
+// resultState turns the JSON output of a crawl into a crawl state.
+// Pages which could not be retrieved are known from the broken links.
+func resultState(r *Result) *crawlState {
+	st := &crawlState{
+		BaseURL:  r.BaseURL,
+		Links:    map[string][]string{},
+		Depths:   map[string]int8{},
+		Statuses: map[string]int{},
+	}
+	for _, p := range r.Pages {
+		links := p.Links
+		if links == nil {
+			links = []string{}
+		}
+		st.Links[p.URL] = links
+		st.Depths[p.URL] = p.Depth
+		if p.Status != 0 {
+			st.Statuses[p.URL] = p.Status
+		}
+		if p.Depth > st.Depth {
+			st.Depth = p.Depth
+		}
+	}
+	for _, b := range r.BrokenLinks {
+		st.Statuses[b.To] = b.Status
+	}
+	return st
+}
+
+func diffStates(old, curr *crawlState) *CrawlDiff {
+	d := &CrawlDiff{}
+
+	for _, u := range sortedPages(curr.Links) {
+		oldLinks, ok := old.Links[u]
+		if !ok {
+			d.Added = append(d.Added, u)
+			continue
+		}
+		added, removed := diffLinks(oldLinks, curr.Links[u])
+		if len(added) > 0 || len(removed) > 0 {
+			d.LinksChanged = append(d.LinksChanged, LinksChange{
+				URL:     u,
+				Added:   added,
+				Removed: removed,
+			})
+		}
+		if old.Depths[u] != curr.Depths[u] {
+			d.DepthChanged = append(d.DepthChanged, DepthChange{
+				URL: u,
+				Old: old.Depths[u],
+				New: curr.Depths[u],
+			})
+		}
+	}
+	for _, u := range sortedPages(old.Links) {
+		if _, ok := curr.Links[u]; !ok {
+			d.Removed = append(d.Removed, u)
+		}
+	}
+
+	statusURLs := []string{}
+	for u := range curr.Statuses {
+		statusURLs = append(statusURLs, u)
+	}
+	sort.Strings(statusURLs)
+	for _, u := range statusURLs {
+		oldStatus, ok := old.Statuses[u]
+		if ok && oldStatus != curr.Statuses[u] {
+			d.StatusChanged = append(d.StatusChanged, StatusChange{
+				URL: u,
+				Old: oldStatus,
+				New: curr.Statuses[u],
+			})
+		}
+	}
+
+	oldBroken := map[BrokenLink]struct{}{}
+	for _, b := range brokenLinks(old) {
+		oldBroken[BrokenLink{From: b.From, To: b.To}] = struct{}{}
+	}
+	for _, b := range brokenLinks(curr) {
+		if _, ok := oldBroken[BrokenLink{From: b.From, To: b.To}]; !ok {
+			d.NewBrokenLinks = append(d.NewBrokenLinks, b)
+		}
+	}
+
+	return d
+}
+
+// diffLinks returns links added to and removed from a page
+func diffLinks(old, curr []string) ([]string, []string) {
+	oldSet := map[string]struct{}{}
+	for _, l := range old {
+		oldSet[l] = struct{}{}
+	}
+	currSet := map[string]struct{}{}
+	for _, l := range curr {
+		currSet[l] = struct{}{}
+	}
+
+	added := []string{}
+	for _, l := range curr {
+		if _, ok := oldSet[l]; !ok {
+			added = append(added, l)
+		}
+	}
+	removed := []string{}
+	for _, l := range old {
+		if _, ok := currSet[l]; !ok {
+			removed = append(removed, l)
+		}
+	}
+	return added, removed
+}
+
+// brokenLinks returns links of a crawl pointing to pages, which
+// were requested, but could not be retrieved
+func brokenLinks(st *crawlState) []BrokenLink {
+	broken := []BrokenLink{}
+	for _, from := range sortedPages(st.Links) {
+		for _, to := range st.Links[from] {
+			status, ok := st.Statuses[to]
+			if !ok {
+				continue
+			}
+			if status == 0 || status >= 400 {
+				broken = append(broken, BrokenLink{
+					From:   from,
+					To:     to,
+					Status: status,
+				})
+			}
+		}
+	}
+	return broken
+}
+
+// sortedPages returns urls of the crawled pages in alphabetical order
+func sortedPages(links map[string][]string) []string {
+	pages := []string{}
+	for u := range links {
+		pages = append(pages, u)
+	}
+	sort.Strings(pages)
+	return pages
+}
+
+// Empty returns true if the crawls do not differ
+func (d *CrawlDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.LinksChanged) == 0 && len(d.NewBrokenLinks) == 0 &&
+		len(d.StatusChanged) == 0 && len(d.DepthChanged) == 0
+}
+
+// Display displays the differences between the crawls
+func (d *CrawlDiff) Display(w io.Writer) {
+	fmt.Fprint(w, "👍 Crawl diff 👍\n\n")
+
+	fmt.Fprintf(w, "   pages added = %d\n", len(d.Added))
+	for _, u := range d.Added {
+		fmt.Fprintf(w, "      + [%s]\n", u)
+	}
+	fmt.Fprintf(w, "   pages removed = %d\n", len(d.Removed))
+	for _, u := range d.Removed {
+		fmt.Fprintf(w, "      - [%s]\n", u)
+	}
+	fmt.Fprintf(w, "   pages with changed links = %d\n", len(d.LinksChanged))
+	for _, c := range d.LinksChanged {
+		fmt.Fprintf(w, "      * [%s]\n", c.URL)
+		for _, l := range c.Added {
+			fmt.Fprintf(w, "         + [%s]\n", l)
+		}
+		for _, l := range c.Removed {
+			fmt.Fprintf(w, "         - [%s]\n", l)
+		}
+	}
+	fmt.Fprintf(w, "   new broken links = %d\n", len(d.NewBrokenLinks))
+	for _, b := range d.NewBrokenLinks {
+		fmt.Fprintf(w, "      ! [%s] -> [%s] (status %d)\n", b.From, b.To, b.Status)
+	}
+	fmt.Fprintf(w, "   status changes = %d\n", len(d.StatusChanged))
+	for _, c := range d.StatusChanged {
+		fmt.Fprintf(w, "      * [%s] %d -> %d\n", c.URL, c.Old, c.New)
+	}
+	fmt.Fprintf(w, "   depth changes = %d\n", len(d.DepthChanged))
+	for _, c := range d.DepthChanged {
+		fmt.Fprintf(w, "      * [%s] %d -> %d\n", c.URL, c.Old, c.New)
+	}
+
+	fmt.Fprintln(w, "\n👍 The END 👍")
+}
@@ -0,0 +1,200 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffCrawls(t *testing.T) {
+	old := &crawlState{
+		BaseURL: testBaseURL,
+		Links: map[string][]string{
+			"https://mmmmm.com":       []string{"https://mmmmm.com/faq", "https://mmmmm.com/about"},
+			"https://mmmmm.com/faq":   []string{"https://mmmmm.com/about"},
+			"https://mmmmm.com/about": []string{"https://mmmmm.com/careers"},
+			"https://mmmmm.com/info":  []string{},
+		},
+		Depths: map[string]int8{
+			"https://mmmmm.com":       0,
+			"https://mmmmm.com/faq":   1,
+			"https://mmmmm.com/about": 1,
+			"https://mmmmm.com/info":  2,
+		},
+		Statuses: map[string]int{
+			"https://mmmmm.com":         200,
+			"https://mmmmm.com/faq":     200,
+			"https://mmmmm.com/about":   200,
+			"https://mmmmm.com/info":    200,
+			"https://mmmmm.com/careers": 404,
+		},
+	}
+	curr := &crawlState{
+		BaseURL: testBaseURL,
+		Links: map[string][]string{
+			"https://mmmmm.com":         []string{"https://mmmmm.com/faq"},
+			"https://mmmmm.com/faq":     []string{"https://mmmmm.com/about", "https://mmmmm.com/generic"},
+			"https://mmmmm.com/about":   []string{"https://mmmmm.com/careers"},
+			"https://mmmmm.com/careers": []string{},
+		},
+		Depths: map[string]int8{
+			"https://mmmmm.com":         0,
+			"https://mmmmm.com/faq":     1,
+			"https://mmmmm.com/about":   2,
+			"https://mmmmm.com/careers": 3,
+		},
+		Statuses: map[string]int{
+			"https://mmmmm.com":         200,
+			"https://mmmmm.com/faq":     200,
+			"https://mmmmm.com/about":   200,
+			"https://mmmmm.com/careers": 200,
+			"https://mmmmm.com/generic": 500,
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "crawler-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldDir := filepath.Join(dir, "old")
+	if err := saveState(oldDir, old); err != nil {
+		t.Fatal(err)
+	}
+	newDir := filepath.Join(dir, "new")
+	if err := saveState(newDir, curr); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &CrawlDiff{
+		Added:   []string{"https://mmmmm.com/careers"},
+		Removed: []string{"https://mmmmm.com/info"},
+		LinksChanged: []LinksChange{
+			{
+				URL:     "https://mmmmm.com",
+				Added:   []string{},
+				Removed: []string{"https://mmmmm.com/about"},
+			},
+			{
+				URL:     "https://mmmmm.com/faq",
+				Added:   []string{"https://mmmmm.com/generic"},
+				Removed: []string{},
+			},
+		},
+		NewBrokenLinks: []BrokenLink{
+			{From: "https://mmmmm.com/faq", To: "https://mmmmm.com/generic", Status: 500},
+		},
+		StatusChanged: []StatusChange{
+			{URL: "https://mmmmm.com/careers", Old: 404, New: 200},
+		},
+		DepthChanged: []DepthChange{
+			{URL: "https://mmmmm.com/about", Old: 1, New: 2},
+		},
+	}
+
+	// a saved crawl can be given as a directory or a file
+	got, err := DiffCrawls(oldDir, filepath.Join(newDir, stateFile))
+	if err != nil {
+		t.Fatalf("TestDiffCrawls error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestDiffCrawls = %+v, want %+v", got, want)
+	}
+
+	data, _ := json.Marshal(got.StatusChanged[0])
+	if string(data) != `{"url":"https://mmmmm.com/careers","old":404,"new":200}` {
+		t.Errorf("TestDiffCrawls status change json = %s", data)
+	}
+	var fields map[string]json.RawMessage
+	data, _ = json.Marshal(got)
+	json.Unmarshal(data, &fields)
+	for _, f := range []string{"added", "removed", "links_changed", "new_broken_links", "status_changed", "depth_changed"} {
+		if _, ok := fields[f]; !ok {
+			t.Errorf("TestDiffCrawls json = %s, want field %s", data, f)
+		}
+	}
+
+	same, err := DiffCrawls(newDir, newDir)
+	if err != nil {
+		t.Fatalf("TestDiffCrawls error = %v", err)
+	}
+	if !same.Empty() {
+		t.Errorf("TestDiffCrawls of identical crawls = %+v, want no differences", same)
+	}
+
+	if _, err := DiffCrawls(oldDir, filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("TestDiffCrawls expected an error for a missing crawl")
+	}
+}
+
+func TestDiffCrawls_output(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yesterday := mockServer(nil)
+	defer yesterday.Close()
+	today := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/generic" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mockHandler(nil)(w, r)
+	}))
+	defer today.Close()
+
+	// both sites are crawled as the same base url
+	crawlJSON := func(ts *httptest.Server, name string) string {
+		cc := newTestCreeper(ts.URL)
+		cc.Logger, _ = NewLogger(ioutil.Discard, "error", FormatText)
+		cc.crawl(cc.fetch())
+		var out bytes.Buffer
+		if err := cc.Output(&out, FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, name)
+		data := strings.Replace(out.String(), ts.URL, testBaseURL, -1)
+		ioutil.WriteFile(file, []byte(data), 0644)
+		return file
+	}
+	old := crawlJSON(yesterday, "yesterday.json")
+	curr := crawlJSON(today, "today.json")
+
+	want := &CrawlDiff{
+		Removed: []string{"https://mmmmm.com/generic"},
+		NewBrokenLinks: []BrokenLink{
+			{From: "https://mmmmm.com/careers", To: "https://mmmmm.com/generic", Status: 500},
+			{From: "https://mmmmm.com/info", To: "https://mmmmm.com/generic", Status: 500},
+		},
+		StatusChanged: []StatusChange{
+			{URL: "https://mmmmm.com/generic", Old: 200, New: 500},
+		},
+	}
+	got, err := DiffCrawls(old, curr)
+	if err != nil {
+		t.Fatalf("TestDiffCrawls_output error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestDiffCrawls_output = %+v, want %+v", got, want)
+	}
+	if same, _ := DiffCrawls(old, old); same == nil || !same.Empty() {
+		t.Errorf("TestDiffCrawls_output of identical crawls = %+v, want no differences", same)
+	}
+
+	// other JSON files are not crawls
+	empty := filepath.Join(dir, "empty.json")
+	ioutil.WriteFile(empty, []byte(`{}`), 0644)
+	if _, err := DiffCrawls(empty, curr); err == nil || !strings.HasPrefix(err.Error(), ErrUnknownCrawl.Error()) {
+		t.Errorf("TestDiffCrawls_output error = %v, want %v", err, ErrUnknownCrawl)
+	}
+}
@@ -0,0 +1,233 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"math/bits"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultSimilarity = 0.9
+	shingleSize       = 3
+)
+
+var (
+	invisibleRegex = regexp.MustCompile(`(?is)<(script|style|noscript|template)[^>]*>.*?</(script|style|noscript|template)>|<!--.*?-->|<head[^>]*>.*?</head>`)
+	tagRegex       = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// fingerprint identifies the visible text of a page
+type fingerprint struct {
+	Hash    string `json:"hash"`
+	SimHash uint64 `json:"simhash"`
+}
+
+// DuplicateReport lists pages with the same or similar visible text
+type DuplicateReport struct {
+	// Exact are groups of pages with identical visible text
+	Exact [][]string `json:"exact"`
+	// Near are groups of pages with similar, but not identical text
+	Near []NearDuplicates `json:"near"`
+}
+
+// NearDuplicates are pages similar to each other at least
+// to the given degree
+type NearDuplicates struct {
+	Pages      []string `json:"pages"`
+	Similarity float64  `json:"similarity"`
+}
+
+// visibleText returns the text of a page, as displayed by a browser
+func visibleText(body string) string {
+	text := invisibleRegex.ReplaceAllString(body, " ")
+	text = tagRegex.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// newFingerprint computes the exact hash and the SimHash
+// of the visible text of a page
+func newFingerprint(body string) fingerprint {
+	text := visibleText(body)
+	sum := sha256.Sum256([]byte(text))
+
+	return fingerprint{
+		Hash:    hex.EncodeToString(sum[:]),
+		SimHash: simHash(strings.Fields(strings.ToLower(text))),
+	}
+}
+
+// simHash computes a 64 bit SimHash of word shingles
+func simHash(words []string) uint64 {
+	var weights [64]int
+
+	shingles := len(words) - shingleSize + 1
+	if shingles < 1 {
+		shingles = 1
+	}
+	for i := 0; i < shingles; i++ {
+		end := i + shingleSize
+		if end > len(words) {
+			end = len(words)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:end], " ")))
+		sum := h.Sum64()
+
+		for b := uint(0); b < 64; b++ {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var hash uint64
+	for b := uint(0); b < 64; b++ {
+		if weights[b] > 0 {
+			hash |= 1 << b
+		}
+	}
+	return hash
+}
+
+// similarity of two SimHashes, from 0 to 1
+func similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// recordFingerprint stores the fingerprint of a fetched page,
+// if duplicates are looked for
+func (cc *Creeper) recordFingerprint(url, body string) {
+	if !cc.Duplicates {
+		return
+	}
+	fp := newFingerprint(body)
+
+	cc.muSeen.Lock()
+	cc.fingerprints[url] = fp
+	cc.muSeen.Unlock()
+}
+
+// FindDuplicates groups crawled pages with identical visible text
+// and clusters pages at least minSimilarity similar (default 0.9)
+func (cc *Creeper) FindDuplicates(minSimilarity float64) *DuplicateReport {
+	if minSimilarity <= 0 || minSimilarity > 1 {
+		minSimilarity = defaultSimilarity
+	}
+
+	cc.muSeen.Lock()
+	fps := map[string]fingerprint{}
+	for u, fp := range cc.fingerprints {
+		fps[u] = fp
+	}
+	cc.muSeen.Unlock()
+
+	pages := []string{}
+	for u := range fps {
+		pages = append(pages, u)
+	}
+	sort.Strings(pages)
+
+	r := &DuplicateReport{
+		Exact: [][]string{},
+		Near:  []NearDuplicates{},
+	}
+
+	// one representative page for each distinct text
+	byHash := map[string][]string{}
+	distinct := []string{}
+	for _, u := range pages {
+		h := fps[u].Hash
+		if _, ok := byHash[h]; !ok {
+			distinct = append(distinct, u)
+		}
+		byHash[h] = append(byHash[h], u)
+	}
+	for _, u := range distinct {
+		if group := byHash[fps[u].Hash]; len(group) > 1 {
+			r.Exact = append(r.Exact, group)
+		}
+	}
+
+	// cluster similar texts
+	parent := map[string]string{}
+	for _, u := range distinct {
+		parent[u] = u
+	}
+	var root func(u string) string
+	root = func(u string) string {
+		if parent[u] != u {
+			parent[u] = root(parent[u])
+		}
+		return parent[u]
+	}
+	for i, a := range distinct {
+		for _, b := range distinct[i+1:] {
+			if similarity(fps[a].SimHash, fps[b].SimHash) >= minSimilarity {
+				parent[root(b)] = root(a)
+			}
+		}
+	}
+
+	clusters := map[string][]string{}
+	roots := []string{}
+	for _, u := range distinct {
+		rt := root(u)
+		if _, ok := clusters[rt]; !ok {
+			roots = append(roots, rt)
+		}
+		clusters[rt] = append(clusters[rt], u)
+	}
+	for _, rt := range roots {
+		texts := clusters[rt]
+		if len(texts) < 2 {
+			continue
+		}
+		near := NearDuplicates{
+			Pages:      []string{},
+			Similarity: 1,
+		}
+		for i, a := range texts {
+			near.Pages = append(near.Pages, byHash[fps[a].Hash]...)
+			for _, b := range texts[i+1:] {
+				if s := similarity(fps[a].SimHash, fps[b].SimHash); s < near.Similarity {
+					near.Similarity = s
+				}
+			}
+		}
+		sort.Strings(near.Pages)
+		r.Near = append(r.Near, near)
+	}
+
+	return r
+}
+
+// Display displays duplicate and near duplicate pages
+func (r *DuplicateReport) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 Duplicate content 👍\n\n")
+
+	fmt.Fprintf(w, "   groups of identical pages = %d\n", len(r.Exact))
+	for i, group := range r.Exact {
+		fmt.Fprintf(w, "      - %d - %d pages\n", i, len(group))
+		for _, u := range group {
+			fmt.Fprintf(w, "         [%s]\n", u)
+		}
+	}
+	fmt.Fprintf(w, "   groups of similar pages = %d\n", len(r.Near))
+	for i, near := range r.Near {
+		fmt.Fprintf(w, "      - %d - %d pages, similarity at least %.2f\n", i, len(near.Pages), near.Similarity)
+		for _, u := range near.Pages {
+			fmt.Fprintf(w, "         [%s]\n", u)
+		}
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,77 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVisibleText(t *testing.T) {
+	body := `<html><head><title>Mmmmm</title><style>p { color: red; }</style></head>
+	<body><script>var a = "<p>hidden</p>";</script><!-- comment -->
+	<h1>Use your   card</h1><p>with&nbsp;Apple&amp;Pay</p></body></html>`
+
+	want := "Use your card with Apple&Pay"
+	if got := visibleText(body); got != want {
+		t.Errorf("TestVisibleText = %q, want %q", got, want)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a := simHash([]string{"apple", "pay", "is", "an", "easy", "secure", "and", "private", "way", "to", "pay", "for", "things"})
+	b := simHash([]string{"apple", "pay", "is", "an", "easy", "secure", "and", "private", "way", "to", "pay", "for", "stuff"})
+	c := simHash([]string{"careers", "at", "mmmmm", "join", "our", "team", "of", "engineers", "and", "designers"})
+
+	if s := similarity(a, a); s != 1 {
+		t.Errorf("TestSimilarity of identical texts = %f, want 1", s)
+	}
+	if similarity(a, b) <= similarity(a, c) {
+		t.Errorf("TestSimilarity of similar texts = %f, want more than of different texts = %f", similarity(a, b), similarity(a, c))
+	}
+}
+
+func TestCreeper_FindDuplicates(t *testing.T) {
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+		Duplicates:    true,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	got := cc.FindDuplicates(0.7)
+	wantExact := [][]string{
+		{
+			"https://mmmmm.com",
+			"https://mmmmm.com/about",
+			"https://mmmmm.com/careers",
+			"https://mmmmm.com/faq",
+			"https://mmmmm.com/info",
+		},
+	}
+	if !reflect.DeepEqual(got.Exact, wantExact) {
+		t.Errorf("TestCreeper_FindDuplicates exact = %v, want %v", got.Exact, wantExact)
+	}
+	if len(got.Near) != 1 {
+		t.Fatalf("TestCreeper_FindDuplicates near = %+v, want one group", got.Near)
+	}
+	wantNear := []string{
+		"https://mmmmm.com",
+		"https://mmmmm.com/about",
+		"https://mmmmm.com/careers",
+		"https://mmmmm.com/faq",
+		"https://mmmmm.com/generic",
+		"https://mmmmm.com/info",
+	}
+	if !reflect.DeepEqual(got.Near[0].Pages, wantNear) {
+		t.Errorf("TestCreeper_FindDuplicates near = %v, want %v", got.Near[0].Pages, wantNear)
+	}
+	if got.Near[0].Similarity < 0.7 || got.Near[0].Similarity >= 1 {
+		t.Errorf("TestCreeper_FindDuplicates near similarity = %f, want from 0.7 to 1", got.Near[0].Similarity)
+	}
+
+	if got := cc.FindDuplicates(1); len(got.Near) != 0 {
+		t.Errorf("TestCreeper_FindDuplicates near at similarity 1 = %+v, want none", got.Near)
+	}
+}
@@ -0,0 +1,143 @@
+package crawler
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Export formats, besides the output formats
+const (
+	FormatCSV     = "csv"
+	FormatDOT     = "dot"
+	FormatSitemap = "sitemap"
+)
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapURLSet is the sitemaps.org XML sitemap
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Load sets up the crawler with a saved crawl, a state directory
+// or its state file, so that it can be output or exported.
+// A crawl which did not reach its depth ended interrupted.
+func (cc *Creeper) Load(crawlPath string) error {
+	st, err := loadCrawl(crawlPath)
+	if err != nil {
+		return err
+	}
+
+	cc.BaseURL = st.BaseURL
+	crawlerInit(cc)
+	cc.restoreState(st)
+
+	for _, d := range st.Depths {
+		if d > cc.Depth {
+			cc.Depth = d
+		}
+	}
+	cc.EndReason = EndInterrupted
+	if len(st.Frontier) == 0 || st.Level > st.Depth {
+		cc.EndReason = EndCompleted
+	}
+	return nil
+}
+
+// Export writes the crawled pages in the given format: text or json,
+// as the crawl output, csv, a row per page, dot, the link graph
+// for Graphviz, or sitemap, an XML sitemap
+func (cc *Creeper) Export(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return cc.exportCSV(w)
+	case FormatDOT:
+		return cc.exportDOT(w)
+	case FormatSitemap:
+		return cc.WriteSitemap(w)
+	}
+	return cc.Output(w, format)
+}
+
+// exportCSV writes the url, depth, status code and number
+// of links of every page
+func (cc *Creeper) exportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"url", "depth", "status", "links"})
+
+	cc.muSeen.Lock()
+	for _, u := range cc.pageOrder() {
+		cw.Write([]string{
+			u,
+			strconv.Itoa(int(cc.seenDepths[u])),
+			strconv.Itoa(cc.statuses[u]),
+			strconv.Itoa(len(cc.seenLinks[u])),
+		})
+	}
+	cc.muSeen.Unlock()
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportDOT writes the links between the pages as a directed graph
+func (cc *Creeper) exportDOT(w io.Writer) error {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	if _, err := fmt.Fprintln(w, "digraph crawl {"); err != nil {
+		return err
+	}
+	for _, u := range cc.pageOrder() {
+		if len(cc.seenLinks[u]) == 0 {
+			fmt.Fprintf(w, "  %q;\n", u)
+		}
+		for _, l := range cc.seenLinks[u] {
+			fmt.Fprintf(w, "  %q -> %q;\n", u, l)
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// WriteSitemap writes an XML sitemap of the crawled pages which
+// could be retrieved, with their last modification dates when
+// the site provided them
+func (cc *Creeper) WriteSitemap(w io.Writer) error {
+	set := sitemapURLSet{Xmlns: sitemapNamespace}
+
+	cc.muSeen.Lock()
+	for _, u := range cc.pageOrder() {
+		if status, ok := cc.statuses[u]; ok && (status == 0 || status >= 400) {
+			continue
+		}
+		su := sitemapURL{Loc: u}
+		if t, err := http.ParseTime(cc.validators[u].LastModified); err == nil {
+			su.LastMod = t.UTC().Format("2006-01-02")
+		}
+		set.URLs = append(set.URLs, su)
+	}
+	cc.muSeen.Unlock()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCreeper_Export(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	crawled := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	inputCheck(crawled)
+	crawlerInit(crawled)
+	crawled.crawl(mockFetch(testBaseURL))
+
+	st := crawled.snapshot()
+	st.Statuses["https://mmmmm.com/careers"] = 404
+	st.Validators["https://mmmmm.com/faq"] = validator{LastModified: "Wed, 21 Oct 2015 07:28:00 GMT"}
+	if err := saveState(dir, st); err != nil {
+		t.Fatal(err)
+	}
+
+	cc := &Creeper{Deterministic: true}
+	if err := cc.Load(dir); err != nil {
+		t.Fatalf("TestCreeper_Export Load error = %v", err)
+	}
+	if cc.BaseURL != testBaseURL || cc.Depth != 5 || cc.EndReason != EndCompleted {
+		t.Errorf("TestCreeper_Export Load = %s depth %d %s, want %s depth 5 %s",
+			cc.BaseURL, cc.Depth, cc.EndReason, testBaseURL, EndCompleted)
+	}
+
+	tests := []struct {
+		format  string
+		want    []string
+		notWant string
+		wantErr error
+	}{
+		{
+			format: FormatText,
+			want:   []string{"[https://mmmmm.com/generic]", ">> The crawl ended: completed <<"},
+		},
+		{
+			format: FormatJSON,
+			want:   []string{`"base_url": "https://mmmmm.com"`, `"url": "https://mmmmm.com/info"`},
+		},
+		{
+			format: FormatCSV,
+			want: []string{
+				"url,depth,status,links\nhttps://mmmmm.com,0,0,2\n",
+				"https://mmmmm.com/careers,2,404,",
+			},
+		},
+		{
+			format: FormatDOT,
+			want: []string{
+				"digraph crawl {\n",
+				`  "https://mmmmm.com" -> "https://mmmmm.com/faq";`,
+				"}\n",
+			},
+		},
+		{
+			format: FormatSitemap,
+			want: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+				"<loc>https://mmmmm.com/faq</loc>\n    <lastmod>2015-10-21</lastmod>",
+				"<loc>https://mmmmm.com/generic</loc>",
+			},
+			notWant: "https://mmmmm.com/careers",
+		},
+		{
+			format:  "xml",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := cc.Export(&out, tt.format); err != tt.wantErr {
+				t.Fatalf("TestCreeper_Export error = %v, want %v", err, tt.wantErr)
+			}
+			got := out.String()
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("TestCreeper_Export does not contain %q:\n%s", w, got)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Errorf("TestCreeper_Export contains %q:\n%s", tt.notWant, got)
+			}
+		})
+	}
+}
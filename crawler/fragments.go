@@ -0,0 +1,157 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var anyTagRegex = regexp.MustCompile(`(?is)<([a-z][a-z0-9]*)(\s[^>]*)?>`)
+
+// FragmentLink is a link to a fragment of a crawled page
+type FragmentLink struct {
+	Page     string `json:"page"`
+	URL      string `json:"url"`
+	Fragment string `json:"fragment"`
+}
+
+// FragmentReport lists fragment links pointing at anchors,
+// which do not exist on their target pages
+type FragmentReport struct {
+	Checked int            `json:"checked"`
+	Broken  []FragmentLink `json:"broken"`
+}
+
+// extractFragments returns the anchors a page defines, ids of any element
+// and names of a elements, and its links to fragments of pages of the site
+func extractFragments(pageURL, body string) (anchors []string, links []string) {
+	anchors, links = []string{}, []string{}
+	seen := map[string]struct{}{}
+
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return anchors, links
+	}
+
+	for _, m := range anyTagRegex.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(m[1])
+		attrs := parseAttrs(m[2])
+
+		for _, a := range []string{attrs["id"], anchorName(name, attrs)} {
+			if _, ok := seen[a]; a == "" || ok {
+				continue
+			}
+			seen[a] = struct{}{}
+			anchors = append(anchors, a)
+		}
+
+		href := attrs["href"]
+		if name != "a" || !strings.Contains(href, "#") {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			continue
+		}
+		u = page.ResolveReference(u)
+		if u.Host != page.Host || u.Fragment == "" {
+			continue
+		}
+		links = append(links, u.String())
+	}
+	return anchors, links
+}
+
+func anchorName(tag string, attrs map[string]string) string {
+	if tag != "a" {
+		return ""
+	}
+	return attrs["name"]
+}
+
+// recordFragments stores the anchors and the fragment links of a fetched
+// page, if fragments are checked
+func (cc *Creeper) recordFragments(url, body string) {
+	if !cc.Fragments {
+		return
+	}
+	anchors, links := extractFragments(url, body)
+
+	cc.muSeen.Lock()
+	cc.anchors[url] = anchors
+	cc.fragmentLinks[url] = links
+	cc.muSeen.Unlock()
+}
+
+// CheckFragments verifies that fragment links of the crawled pages
+// point at anchors defined on their target pages. Links to pages,
+// which were not crawled, cannot be verified and are left out.
+func (cc *Creeper) CheckFragments() *FragmentReport {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	pages := []string{}
+	for p := range cc.fragmentLinks {
+		pages = append(pages, p)
+	}
+	sort.Strings(pages)
+
+	r := &FragmentReport{
+		Broken: []FragmentLink{},
+	}
+	for _, p := range pages {
+		for _, l := range cc.fragmentLinks[p] {
+			u, err := url.Parse(l)
+			if err != nil {
+				continue
+			}
+			fragment := u.Fragment
+			u.Fragment = ""
+
+			anchors, ok := cc.anchors[u.String()]
+			if !ok && u.Path == "/" {
+				// the base url may have been crawled without the slash
+				u.Path = ""
+				anchors, ok = cc.anchors[u.String()]
+			}
+			if !ok {
+				continue
+			}
+
+			r.Checked++
+			if fragment == "top" || containsString(anchors, fragment) {
+				continue
+			}
+			r.Broken = append(r.Broken, FragmentLink{
+				Page:     p,
+				URL:      l,
+				Fragment: fragment,
+			})
+		}
+	}
+	return r
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Display displays fragment links with missing anchors
+func (r *FragmentReport) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 Fragment links 👍\n\n")
+
+	fmt.Fprintf(w, "   checked fragment links = %d\n", r.Checked)
+	fmt.Fprintf(w, "   broken fragment links = %d\n", len(r.Broken))
+	for _, l := range r.Broken {
+		fmt.Fprintf(w, "      ! [%s] -> #%s [%s]\n", l.Page, l.Fragment, l.URL)
+	}
+	fmt.Fprintln(w)
+}
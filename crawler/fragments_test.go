@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractFragments(t *testing.T) {
+	body := `<html>
+  <body>
+    <h1 id="guide">Guide</h1>
+    <a name="install"></a>
+    <h2 id='usage' class="title">Usage</h2>
+    <input name="query">
+    <p>Start with the <a href="#install">installation</a>, then see <a href="/faq#questions">questions</a>,
+    <a href="/faq">FAQ</a>, <a href="https://other.com/about#team">the team</a> and <a href="#">nothing</a>.</p>
+  </body>
+</html>`
+
+	wantAnchors := []string{"guide", "install", "usage"}
+	wantLinks := []string{
+		"https://mmmmm.com/guide#install",
+		"https://mmmmm.com/faq#questions",
+	}
+	anchors, links := extractFragments("https://mmmmm.com/guide", body)
+	if !reflect.DeepEqual(anchors, wantAnchors) {
+		t.Errorf("TestExtractFragments anchors = %v, want %v", anchors, wantAnchors)
+	}
+	if !reflect.DeepEqual(links, wantLinks) {
+		t.Errorf("TestExtractFragments links = %v, want %v", links, wantLinks)
+	}
+}
+
+func TestCreeper_CheckFragments(t *testing.T) {
+	cc := &Creeper{
+		anchors: map[string][]string{
+			"https://mmmmm.com":       []string{"main"},
+			"https://mmmmm.com/guide": []string{"install", "usage"},
+			"https://mmmmm.com/faq":   []string{},
+		},
+		fragmentLinks: map[string][]string{
+			"https://mmmmm.com": []string{
+				"https://mmmmm.com/guide#install",
+				"https://mmmmm.com/guide#upgrade",
+				"https://mmmmm.com/careers#jobs",
+			},
+			"https://mmmmm.com/faq": []string{
+				"https://mmmmm.com/#main",
+				"https://mmmmm.com/faq#top",
+				"https://mmmmm.com/faq#questions",
+			},
+		},
+	}
+
+	want := &FragmentReport{
+		Checked: 5,
+		Broken: []FragmentLink{
+			{Page: "https://mmmmm.com", URL: "https://mmmmm.com/guide#upgrade", Fragment: "upgrade"},
+			{Page: "https://mmmmm.com/faq", URL: "https://mmmmm.com/faq#questions", Fragment: "questions"},
+		},
+	}
+	if got := cc.CheckFragments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_CheckFragments = %+v, want %+v", got, want)
+	}
+}
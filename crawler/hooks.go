@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Reasons why a url was not crawled
+const (
+	SkipDepth    = "beyond maximum depth"
+	SkipBudget   = "budget exhausted"
+	SkipVetoed   = "vetoed by OnRequest"
+	SkipRedirect = "redirect link"
+	SkipScope    = "outside the scope"
+)
+
+var (
+	ErrRequestVetoed = errors.New("Request vetoed")
+)
+
+// Page is a fetched page with the links found on it
+type Page struct {
+	URL   string
+	Depth int8
+	Body  string
+	Links []string
+}
+
+// Hooks are callbacks invoked as the crawl goes on. They are called
+// concurrently, unless the crawl is deterministic, and must not block
+// for long. Any of them can be left nil.
+type Hooks struct {
+	// OnRequest is called before a page is requested. It can modify
+	// the request, e.g. add headers, or veto it by returning false
+	OnRequest func(req *http.Request) bool
+	// OnResponse is called with the response headers, before
+	// the body is read by the crawler
+	OnResponse func(res *http.Response)
+	// OnPage is called with every fetched page and its links
+	OnPage func(p Page)
+	// OnError is called when a page cannot be fetched
+	OnError func(url string, err error)
+	// OnSkip is called when a url is not crawled, with the reason
+	OnSkip func(url string, reason string)
+}
+
+func (cc *Creeper) onRequest(req *http.Request) bool {
+	if cc.Hooks.OnRequest == nil {
+		return true
+	}
+	return cc.Hooks.OnRequest(req)
+}
+
+func (cc *Creeper) onResponse(res *http.Response) {
+	if cc.Hooks.OnResponse != nil {
+		cc.Hooks.OnResponse(res)
+	}
+}
+
+func (cc *Creeper) onPage(p Page) {
+	if cc.Hooks.OnPage != nil {
+		cc.Hooks.OnPage(p)
+	}
+}
+
+func (cc *Creeper) onError(url string, err error) {
+	if cc.Hooks.OnError != nil {
+		cc.Hooks.OnError(url, err)
+	}
+}
+
+func (cc *Creeper) onSkip(url, reason string) {
+	if cc.Hooks.OnSkip != nil {
+		cc.Hooks.OnSkip(url, reason)
+	}
+}
@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCreeper_Hooks(t *testing.T) {
+	var headers []string
+	var muHeaders sync.Mutex
+	handler := mockHandler(nil)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		muHeaders.Lock()
+		headers = append(headers, r.Header.Get("X-Crawler"))
+		muHeaders.Unlock()
+		handler(w, r)
+	}))
+	defer ts.Close()
+
+	var mu sync.Mutex
+	pages := []string{}
+	responses := map[string]int{}
+	errs := []string{}
+	skips := map[string]string{}
+
+	cc := newTestCreeper(ts.URL)
+	cc.Depth = 1
+	cc.Hooks = Hooks{
+		OnRequest: func(req *http.Request) bool {
+			switch req.URL.Path {
+			case "/faq":
+				return false
+			case "/about":
+				req.URL.Path = "/missing"
+			}
+			req.Header.Set("X-Crawler", "creepycrawly")
+			return true
+		},
+		OnResponse: func(res *http.Response) {
+			mu.Lock()
+			responses[res.Request.URL.Path] = res.StatusCode
+			mu.Unlock()
+		},
+		OnPage: func(p Page) {
+			mu.Lock()
+			pages = append(pages, strings.TrimPrefix(p.URL, ts.URL))
+			mu.Unlock()
+		},
+		OnError: func(url string, err error) {
+			mu.Lock()
+			errs = append(errs, strings.TrimPrefix(url, ts.URL))
+			mu.Unlock()
+		},
+		OnSkip: func(url string, reason string) {
+			mu.Lock()
+			skips[strings.TrimPrefix(url, ts.URL)] = reason
+			mu.Unlock()
+		},
+	}
+	cc.crawl(cc.fetch())
+	sort.Strings(pages)
+
+	wantPages := []string{""}
+	if !reflect.DeepEqual(pages, wantPages) {
+		t.Errorf("TestCreeper_Hooks pages = %v, want %v", pages, wantPages)
+	}
+	wantResponses := map[string]int{"": http.StatusOK, "/missing": http.StatusNotFound}
+	if !reflect.DeepEqual(responses, wantResponses) {
+		t.Errorf("TestCreeper_Hooks responses = %v, want %v", responses, wantResponses)
+	}
+	wantErrs := []string{"/about"}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("TestCreeper_Hooks errors = %v, want %v", errs, wantErrs)
+	}
+	wantSkips := map[string]string{"/faq": SkipVetoed}
+	if !reflect.DeepEqual(skips, wantSkips) {
+		t.Errorf("TestCreeper_Hooks skips = %v, want %v", skips, wantSkips)
+	}
+	for _, h := range headers {
+		if h != "creepycrawly" {
+			t.Errorf("TestCreeper_Hooks request header = %q, want %q", h, "creepycrawly")
+		}
+	}
+	if p := cc.Progress(); p.PagesFetched != 2 {
+		t.Errorf("TestCreeper_Hooks pages fetched = %d, want 2", p.PagesFetched)
+	}
+}
+
+func TestCreeper_Hooks_OnSkip(t *testing.T) {
+	skips := map[string]string{}
+
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(1),
+		Deterministic: true,
+		MaxPages:      2,
+		Hooks: Hooks{
+			OnSkip: func(url string, reason string) {
+				skips[url] = reason
+			},
+		},
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	want := map[string]string{
+		"https://mmmmm.com/about": SkipBudget,
+	}
+	if !reflect.DeepEqual(skips, want) {
+		t.Errorf("TestCreeper_Hooks_OnSkip = %v, want %v", skips, want)
+	}
+
+	skips = map[string]string{}
+	cc.MaxPages = 0
+	crawlerInit(cc)
+	cc.extractLinks(testBaseURL, `<a href="/-play-store-redirect">Get the app</a>`)
+	cc.crawl(mockFetch(testBaseURL))
+
+	want = map[string]string{
+		"https://mmmmm.com/-play-store-redirect": SkipRedirect,
+		"https://mmmmm.com/careers":              SkipDepth,
+		"https://mmmmm.com/info":                 SkipDepth,
+	}
+	if !reflect.DeepEqual(skips, want) {
+		t.Errorf("TestCreeper_Hooks_OnSkip = %v, want %v", skips, want)
+	}
+}
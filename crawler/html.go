@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	attrRegex   = regexp.MustCompile(`([a-zA-Z_:][a-zA-Z0-9_:.\-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	tagRegexs   = map[string]*regexp.Regexp{}
+	muTagRegexs sync.Mutex
+)
+
+// tag is an html start tag with its attributes
+type tag struct {
+	name  string
+	attrs map[string]string
+	// start and end are the offsets of the tag in the page
+	start int
+	end   int
+}
+
+// tagRegexFor returns a regex matching start tags of the given name
+func tagRegexFor(name string) *regexp.Regexp {
+	muTagRegexs.Lock()
+	defer muTagRegexs.Unlock()
+
+	r, ok := tagRegexs[name]
+	if !ok {
+		r = regexp.MustCompile(fmt.Sprintf(`(?is)<%s(\s[^>]*)?/?>`, name))
+		tagRegexs[name] = r
+	}
+	return r
+}
+
+// findTags returns start tags of the given name found in the page
+func findTags(body, name string) []tag {
+	tags := []tag{}
+	for _, m := range tagRegexFor(name).FindAllStringSubmatchIndex(body, -1) {
+		attrs := ""
+		if m[2] >= 0 {
+			attrs = body[m[2]:m[3]]
+		}
+		tags = append(tags, tag{
+			name:  strings.ToLower(name),
+			attrs: parseAttrs(attrs),
+			start: m[0],
+			end:   m[1],
+		})
+	}
+	return tags
+}
+
+// parseAttrs parses tag attributes, with lower case names
+// and unescaped values
+func parseAttrs(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range attrRegex.FindAllStringSubmatch(s, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		attrs[name] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// elementTexts returns the visible texts of all elements of the given name
+func elementTexts(body, name string) []string {
+	r := elementRegexFor(name)
+	texts := []string{}
+	for _, m := range r.FindAllStringSubmatch(body, -1) {
+		texts = append(texts, visibleText(m[1]))
+	}
+	return texts
+}
+
+// elementRegexFor returns a regex matching whole elements of the given name
+func elementRegexFor(name string) *regexp.Regexp {
+	key := "/" + name
+
+	muTagRegexs.Lock()
+	defer muTagRegexs.Unlock()
+
+	r, ok := tagRegexs[key]
+	if !ok {
+		r = regexp.MustCompile(fmt.Sprintf(`(?is)<%s(?:\s[^>]*)?>(.*?)</%s\s*>`, name, name))
+		tagRegexs[key] = r
+	}
+	return r
+}
+
+// resolve resolves a reference found on a page against the page url
+func resolve(pageURL, ref string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
+}
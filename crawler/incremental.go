@@ -0,0 +1,174 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	ErrNotModified = errors.New("Page not modified")
+)
+
+// Changes of a page since the previous crawl
+const (
+	ChangeNew       = "new"
+	ChangeModified  = "modified"
+	ChangeUnchanged = "unchanged"
+	ChangeRemoved   = "removed"
+)
+
+// validator holds the response headers used for conditional requests
+type validator struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// previousValidator returns the validator the page had in the previous crawl
+func (cc *Creeper) previousValidator(url string) (validator, bool) {
+	if cc.previous == nil {
+		return validator{}, false
+	}
+	v, ok := cc.previous.Validators[url]
+	if !ok || (v.ETag == "" && v.LastModified == "") {
+		return validator{}, false
+	}
+	return v, true
+}
+
+// recordValidator stores the validator of a fetched page
+func (cc *Creeper) recordValidator(url string, v validator) {
+	if v.ETag == "" && v.LastModified == "" {
+		return
+	}
+	cc.muSeen.Lock()
+	cc.validators[url] = v
+	cc.muSeen.Unlock()
+}
+
+// recordChange stores the content hash of a fetched page and marks
+// the page as new, modified or unchanged compared to the previous
+// crawl. Pages of crawls saved without hashes are compared by links.
+func (cc *Creeper) recordChange(url, body string, links []string) {
+	sum := sha256.Sum256([]byte(body))
+	hash := hex.EncodeToString(sum[:])
+
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	cc.hashes[url] = hash
+	if cc.previous == nil {
+		return
+	}
+	prevLinks, ok := cc.previous.Links[url]
+	if !ok {
+		cc.changes[url] = ChangeNew
+		return
+	}
+	change := ChangeModified
+	if prevHash, ok := cc.previous.Hashes[url]; ok {
+		if prevHash == hash {
+			change = ChangeUnchanged
+		}
+	} else if added, removed := diffLinks(prevLinks, links); len(added) == 0 && len(removed) == 0 {
+		change = ChangeUnchanged
+	}
+	cc.changes[url] = change
+}
+
+// unchangedPage rebuilds a page, which was not modified since
+// the previous crawl, from the links stored in the previous crawl
+func (cc *Creeper) unchangedPage(depth int8, url string) *page {
+	if cc.previous == nil {
+		cc.logger().Warn("page not fetched", "url", url, "depth", depth, "error", ErrNotModified)
+		return nil
+	}
+	links, ok := cc.previous.Links[url]
+	if !ok {
+		cc.logger().Warn("page not fetched", "url", url, "depth", depth, "error", "no stored links")
+		return nil
+	}
+
+	cc.muSeen.Lock()
+	if v, ok := cc.previous.Validators[url]; ok {
+		cc.validators[url] = v
+	}
+	if code, ok := cc.previous.Statuses[url]; ok {
+		cc.statuses[url] = code
+	}
+	if fp, ok := cc.previous.Fingerprints[url]; ok && cc.Duplicates {
+		cc.fingerprints[url] = fp
+	}
+	if h, ok := cc.previous.Hashes[url]; ok {
+		cc.hashes[url] = h
+	}
+	if m, ok := cc.previous.Metadata[url]; ok && cc.SEO {
+		cc.metadata[url] = m
+	}
+	if assets, ok := cc.previous.Assets[url]; ok && cc.Assets {
+		cc.assets[url] = assets
+		for _, a := range assets {
+			if _, ok := cc.assetStatuses[a.URL]; !ok {
+				cc.assetStatuses[a.URL] = cc.previous.AssetStatuses[a.URL]
+			}
+		}
+	}
+	if contexts, ok := cc.previous.LinkContexts[url]; ok && cc.LinkContext {
+		cc.linkContexts[url] = contexts
+	}
+	if anchors, ok := cc.previous.Anchors[url]; ok && cc.Fragments {
+		cc.anchors[url] = anchors
+		cc.fragmentLinks[url] = cc.previous.FragmentLinks[url]
+	}
+	cc.changes[url] = ChangeUnchanged
+	cc.muSeen.Unlock()
+
+	return &page{
+		url:   url,
+		depth: depth,
+		links: links,
+	}
+}
+
+// pageChanges returns crawled pages grouped by their change since
+// the previous crawl, including pages no longer found
+func (cc *Creeper) pageChanges() map[string][]string {
+	changes := map[string][]string{}
+
+	cc.muSeen.Lock()
+	for url, change := range cc.changes {
+		changes[change] = append(changes[change], url)
+	}
+	if cc.previous != nil {
+		for url := range cc.previous.Links {
+			if _, ok := cc.seenLinks[url]; !ok {
+				changes[ChangeRemoved] = append(changes[ChangeRemoved], url)
+			}
+		}
+	}
+	cc.muSeen.Unlock()
+
+	for _, urls := range changes {
+		sort.Strings(urls)
+	}
+	return changes
+}
+
+// displayChanges displays what changed since the previous crawl
+func (cc *Creeper) displayChanges() {
+	changes := cc.pageChanges()
+
+	fmt.Fprint(cc.Out, "\n👍 Changes since the previous crawl 👍\n\n")
+	for _, change := range []string{ChangeNew, ChangeModified, ChangeUnchanged, ChangeRemoved} {
+		fmt.Fprintf(cc.Out, "   %s pages = %d\n", change, len(changes[change]))
+	}
+	fmt.Fprintln(cc.Out, "--------------------------------")
+	for _, change := range []string{ChangeNew, ChangeModified, ChangeRemoved} {
+		for _, url := range changes[change] {
+			fmt.Fprintf(cc.Out, "   - [%s] (%s)\n", url, change)
+		}
+	}
+	fmt.Fprintln(cc.Out)
+}
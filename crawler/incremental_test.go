@@ -0,0 +1,150 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tamarakaufler/go-crawler/crawler/crawlertest"
+)
+
+func newTestCreeper(baseURL string) *Creeper {
+	cc := &Creeper{
+		BaseURL:       baseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+		pageScanner:   regexSetup(baseURL),
+	}
+	cc.baseURLParsed, _ = url.Parse(baseURL)
+	crawlerInit(cc)
+	return cc
+}
+
+func TestCreeper_crawl_incremental(t *testing.T) {
+	etags := map[string]string{
+		"/":        `"base-1"`,
+		"/faq":     `"faq-1"`,
+		"/about":   `"about-1"`,
+		"/info":    `"info-1"`,
+		"/careers": `"careers-1"`,
+		"/generic": `"generic-1"`,
+	}
+	ts := mockServer(etags)
+	defer ts.Close()
+
+	first := newTestCreeper(ts.URL)
+	first.crawl(first.fetch())
+	if len(first.changes) != 0 {
+		t.Errorf("TestCreeper_crawl_incremental first crawl changes = %v, want none", first.changes)
+	}
+
+	// a new ETag with the same content leaves the page unchanged
+	etags["/faq"] = `"faq-2"`
+	second := newTestCreeper(ts.URL)
+	second.previous = first.snapshot()
+	second.crawl(second.fetch())
+
+	if !reflect.DeepEqual(second.seenLinks, first.seenLinks) {
+		t.Errorf("TestCreeper_crawl_incremental = %+v, want %+v", second.seenLinks, first.seenLinks)
+	}
+	want := map[string][]string{
+		ChangeUnchanged: []string{
+			ts.URL,
+			ts.URL + "/about",
+			ts.URL + "/careers",
+			ts.URL + "/faq",
+			ts.URL + "/generic",
+			ts.URL + "/info",
+		},
+	}
+	if got := second.pageChanges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_crawl_incremental changes = %v, want %v", got, want)
+	}
+	if got := second.validators[ts.URL+"/faq"].ETag; got != `"faq-2"` {
+		t.Errorf("TestCreeper_crawl_incremental faq etag = %s, want %s", got, `"faq-2"`)
+	}
+	if second.bytesFetched >= first.bytesFetched {
+		t.Errorf("TestCreeper_crawl_incremental bytes = %d, want less than %d", second.bytesFetched, first.bytesFetched)
+	}
+}
+
+func TestCreeper_crawl_incremental_content(t *testing.T) {
+	var faq atomic.Value
+	faq.Store("")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body := faq.Load().(string); r.URL.Path == "/faq" && body != "" {
+			w.Write([]byte(body))
+			return
+		}
+		mockHandler(nil)(w, r)
+	}))
+	defer ts.Close()
+
+	first := newTestCreeper(ts.URL)
+	first.crawl(first.fetch())
+	withHashes := first.snapshot()
+	withoutHashes := first.snapshot()
+	withoutHashes.Hashes = nil
+
+	original := string(crawlertest.Files()["faq.html"])
+	updated := original + "<!-- updated -->"
+	unchanged := []string{ts.URL, ts.URL + "/about", ts.URL + "/careers", ts.URL + "/generic", ts.URL + "/info"}
+
+	tests := []struct {
+		name     string
+		faq      string
+		previous *crawlState
+		want     map[string][]string
+	}{
+		{
+			name:     "identical content",
+			previous: withHashes,
+			want: map[string][]string{
+				ChangeUnchanged: append([]string{ts.URL + "/faq"}, unchanged...),
+			},
+		},
+		{
+			name:     "changed content",
+			faq:      updated,
+			previous: withHashes,
+			want: map[string][]string{
+				ChangeModified:  []string{ts.URL + "/faq"},
+				ChangeUnchanged: unchanged,
+			},
+		},
+		{
+			name:     "previous crawl without hashes, same links",
+			faq:      updated,
+			previous: withoutHashes,
+			want: map[string][]string{
+				ChangeUnchanged: append([]string{ts.URL + "/faq"}, unchanged...),
+			},
+		},
+		{
+			name:     "previous crawl without hashes, changed links",
+			faq:      original + `<a href="/generic">Generic</a>`,
+			previous: withoutHashes,
+			want: map[string][]string{
+				ChangeModified:  []string{ts.URL + "/faq"},
+				ChangeUnchanged: unchanged,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faq.Store(tt.faq)
+			cc := newTestCreeper(ts.URL)
+			cc.previous = tt.previous
+			cc.crawl(cc.fetch())
+			got := cc.pageChanges()
+			sort.Strings(tt.want[ChangeUnchanged])
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TestCreeper_crawl_incremental_content changes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
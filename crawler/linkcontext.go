@@ -0,0 +1,201 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Regions of a page a link can be found in
+const (
+	RegionNav    = "nav"
+	RegionHeader = "header"
+	RegionFooter = "footer"
+	RegionMain   = "main"
+)
+
+var (
+	regions            = []string{RegionNav, RegionHeader, RegionFooter, RegionMain}
+	anchorEndRegex     = regexp.MustCompile(`(?i)</a\s*>`)
+	genericAnchorTexts = map[string]struct{}{
+		"click here": {},
+		"click":      {},
+		"here":       {},
+		"read more":  {},
+		"more":       {},
+		"learn more": {},
+		"more info":  {},
+		"details":    {},
+		"link":       {},
+		"this link":  {},
+		"this page":  {},
+		"this":       {},
+		"go":         {},
+		"continue":   {},
+	}
+)
+
+// LinkContext describes a link found on a page
+type LinkContext struct {
+	URL string `json:"url"`
+	// Text is the visible text of the link, or the alt text
+	// of its image when the link has no text
+	Text  string   `json:"text"`
+	Title string   `json:"title,omitempty"`
+	Rel   []string `json:"rel,omitempty"`
+	// Region is the innermost nav, header, footer or main element
+	// containing the link, empty when there is none
+	Region string `json:"region,omitempty"`
+}
+
+// extractLinkContexts returns the context of every occurrence
+// of the given links on a page, in the order of their appearance
+func extractLinkContexts(pageURL, body string, links []string) []LinkContext {
+	wanted := map[string]struct{}{}
+	for _, l := range links {
+		wanted[l] = struct{}{}
+	}
+
+	spans := map[string][][]int{}
+	for _, r := range regions {
+		spans[r] = elementRegexFor(r).FindAllStringIndex(body, -1)
+	}
+
+	contexts := []LinkContext{}
+	for _, t := range findTags(body, "a") {
+		href := t.attrs["href"]
+		if href == "" {
+			continue
+		}
+		u, err := resolve(pageURL, href)
+		if err != nil {
+			continue
+		}
+		// links are recorded without their fragments
+		if i := strings.Index(u, "#"); i >= 0 {
+			u = u[:i]
+		}
+		if _, ok := wanted[u]; !ok {
+			continue
+		}
+
+		content := body[t.end:]
+		if loc := anchorEndRegex.FindStringIndex(content); loc != nil {
+			content = content[:loc[0]]
+		}
+		text := visibleText(content)
+		if text == "" {
+			for _, img := range findTags(content, "img") {
+				if alt := strings.Join(strings.Fields(img.attrs["alt"]), " "); alt != "" {
+					text = alt
+					break
+				}
+			}
+		}
+
+		contexts = append(contexts, LinkContext{
+			URL:    u,
+			Text:   text,
+			Title:  strings.Join(strings.Fields(t.attrs["title"]), " "),
+			Rel:    strings.Fields(strings.ToLower(t.attrs["rel"])),
+			Region: region(spans, t.start),
+		})
+	}
+	return contexts
+}
+
+// region returns the innermost region containing the given offset
+func region(spans map[string][][]int, offset int) string {
+	found, start := "", -1
+	for _, r := range regions {
+		for _, s := range spans[r] {
+			if s[0] <= offset && offset < s[1] && s[0] > start {
+				found, start = r, s[0]
+			}
+		}
+	}
+	return found
+}
+
+// recordLinkContexts stores the contexts of the links of a fetched page,
+// if they are collected
+func (cc *Creeper) recordLinkContexts(url, body string, links []string) {
+	if !cc.LinkContext {
+		return
+	}
+	contexts := extractLinkContexts(url, body, links)
+
+	cc.muSeen.Lock()
+	cc.linkContexts[url] = contexts
+	cc.muSeen.Unlock()
+}
+
+// AnchorLink is a link with its anchor text
+type AnchorLink struct {
+	Page string `json:"page"`
+	URL  string `json:"url"`
+	Text string `json:"text"`
+}
+
+// AnchorReport lists links with uninformative anchor texts
+type AnchorReport struct {
+	// Generic are links with texts like "click here" or "read more"
+	Generic []AnchorLink `json:"generic"`
+	// Empty are links with neither text nor image alt text
+	Empty []AnchorLink `json:"empty"`
+}
+
+// isGenericAnchor returns true if the anchor text does not describe
+// the target of the link
+func isGenericAnchor(text string) bool {
+	text = strings.ToLower(strings.Trim(text, " .:!?…»›→>"))
+	_, ok := genericAnchorTexts[text]
+	return ok
+}
+
+// ReportAnchors lists links of the crawled pages with generic
+// or empty anchor texts
+func (cc *Creeper) ReportAnchors() *AnchorReport {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	pages := []string{}
+	for p := range cc.linkContexts {
+		pages = append(pages, p)
+	}
+	sort.Strings(pages)
+
+	r := &AnchorReport{
+		Generic: []AnchorLink{},
+		Empty:   []AnchorLink{},
+	}
+	for _, p := range pages {
+		for _, lc := range cc.linkContexts[p] {
+			l := AnchorLink{Page: p, URL: lc.URL, Text: lc.Text}
+			switch {
+			case lc.Text == "":
+				r.Empty = append(r.Empty, l)
+			case isGenericAnchor(lc.Text):
+				r.Generic = append(r.Generic, l)
+			}
+		}
+	}
+	return r
+}
+
+// Display displays links with generic and empty anchor texts
+func (r *AnchorReport) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 Anchor texts 👍\n\n")
+
+	fmt.Fprintf(w, "   links with generic text = %d\n", len(r.Generic))
+	for _, l := range r.Generic {
+		fmt.Fprintf(w, "      - [%s] -> %q [%s]\n", l.Page, l.Text, l.URL)
+	}
+	fmt.Fprintf(w, "   links without text = %d\n", len(r.Empty))
+	for _, l := range r.Empty {
+		fmt.Fprintf(w, "      - [%s] -> [%s]\n", l.Page, l.URL)
+	}
+	fmt.Fprintln(w)
+}
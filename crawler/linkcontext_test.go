@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractLinkContexts(t *testing.T) {
+	body := `<html>
+  <body>
+    <header>
+      <nav>
+        <a href="/about" title="About  us">About <b>Mmmmm</b></a>
+        <a href="/faq"><img src="/faq.png" alt="FAQ"></a>
+      </nav>
+      <a href="https://mmmmm.com/careers" rel="nofollow Noopener">Careers</a>
+    </header>
+    <main>
+      <p>To find out more <a href='/info'>click here</a>.</p>
+      <a href="/info"><span class="icon"></span></a>
+      <a href="https://other.com/about">Other</a>
+      <a href="/guide#install">Install</a>
+    </main>
+    <footer><a href="/about">Read more &raquo;</a></footer>
+  </body>
+</html>`
+	links := []string{
+		"https://mmmmm.com/about",
+		"https://mmmmm.com/faq",
+		"https://mmmmm.com/careers",
+		"https://mmmmm.com/info",
+		"https://mmmmm.com/guide",
+	}
+
+	want := []LinkContext{
+		{URL: "https://mmmmm.com/about", Text: "About Mmmmm", Title: "About us", Rel: []string{}, Region: RegionNav},
+		{URL: "https://mmmmm.com/faq", Text: "FAQ", Rel: []string{}, Region: RegionNav},
+		{URL: "https://mmmmm.com/careers", Text: "Careers", Rel: []string{"nofollow", "noopener"}, Region: RegionHeader},
+		{URL: "https://mmmmm.com/info", Text: "click here", Rel: []string{}, Region: RegionMain},
+		{URL: "https://mmmmm.com/info", Text: "", Rel: []string{}, Region: RegionMain},
+		{URL: "https://mmmmm.com/guide", Text: "Install", Rel: []string{}, Region: RegionMain},
+		{URL: "https://mmmmm.com/about", Text: "Read more »", Rel: []string{}, Region: RegionFooter},
+	}
+	if got := extractLinkContexts("https://mmmmm.com", body, links); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestExtractLinkContexts = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreeper_ReportAnchors(t *testing.T) {
+	cc := &Creeper{
+		linkContexts: map[string][]LinkContext{
+			"https://mmmmm.com/faq": []LinkContext{
+				{URL: "https://mmmmm.com/about", Text: "Learn more:"},
+				{URL: "https://mmmmm.com/info", Text: "Opening hours"},
+			},
+			"https://mmmmm.com": []LinkContext{
+				{URL: "https://mmmmm.com/about", Text: "About Mmmmm"},
+				{URL: "https://mmmmm.com/info", Text: "Click here"},
+				{URL: "https://mmmmm.com/faq", Text: ""},
+			},
+		},
+	}
+
+	want := &AnchorReport{
+		Generic: []AnchorLink{
+			{Page: "https://mmmmm.com", URL: "https://mmmmm.com/info", Text: "Click here"},
+			{Page: "https://mmmmm.com/faq", URL: "https://mmmmm.com/about", Text: "Learn more:"},
+		},
+		Empty: []AnchorLink{
+			{Page: "https://mmmmm.com", URL: "https://mmmmm.com/faq"},
+		},
+	}
+	if got := cc.ReportAnchors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_ReportAnchors = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package crawler
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownSource = errors.New("Source must be a directory, a .zip or a .tar.gz archive")
+)
+
+// siteFiles are the files of a local static site,
+// by slash separated paths relative to its root
+type siteFiles interface {
+	file(name string) ([]byte, time.Time, bool)
+}
+
+// dirFiles are the files of a directory
+type dirFiles struct {
+	fsys fs.FS
+}
+
+func (d dirFiles) file(name string) ([]byte, time.Time, bool) {
+	fi, err := fs.Stat(d.fsys, name)
+	if err != nil || fi.IsDir() {
+		return nil, time.Time{}, false
+	}
+	data, err := fs.ReadFile(d.fsys, name)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return data, fi.ModTime(), true
+}
+
+// memFile is a file of an archive
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// memFiles are the files of an archive, read into memory
+type memFiles map[string]memFile
+
+func (m memFiles) file(name string) ([]byte, time.Time, bool) {
+	f, ok := m[name]
+	return f.data, f.modTime, ok
+}
+
+// openSource opens a local directory, .zip or .tar.gz archive
+func openSource(source string) (siteFiles, error) {
+	fi, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case fi.IsDir():
+		return dirFiles{fsys: os.DirFS(source)}, nil
+	case strings.HasSuffix(source, ".zip"):
+		return readZip(source)
+	case strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, ".tgz"):
+		return readTarGz(source)
+	}
+	return nil, fmt.Errorf("%v: %s", ErrUnknownSource, source)
+}
+
+// readZip reads the files of a zip archive
+func readZip(file string) (memFiles, error) {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	files := memFiles{}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", file, f.Name, err)
+		}
+		files[archivePath(f.Name)] = memFile{data: data, modTime: f.Modified}
+	}
+	return files, nil
+}
+
+// readTarGz reads the regular files of a gzipped tar archive
+func readTarGz(file string) (memFiles, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := memFiles{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", file, h.Name, err)
+		}
+		files[archivePath(h.Name)] = memFile{data: data, modTime: h.ModTime}
+	}
+	return files, nil
+}
+
+// archivePath turns the name of an archived file, like ./docs/index.html,
+// into its path relative to the site root
+func archivePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// localTransport serves requests to the host of the base URL from
+// the files of a local site. Requests to other hosts go to next.
+type localTransport struct {
+	host  string
+	files siteFiles
+	next  http.RoundTripper
+}
+
+// RoundTrip answers the request as a static file server would.
+// /path/ is served from path/index.html, /path from path,
+// path/index.html or path.html
+func (t *localTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return roundTrip(t.next, req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return localResponse(req, http.StatusMethodNotAllowed, nil, nil), nil
+	}
+
+	p := strings.TrimPrefix(path.Clean("/"+req.URL.Path), "/")
+	candidates := []string{p, path.Join(p, "index.html"), p + ".html"}
+	if p == "" || strings.HasSuffix(req.URL.Path, "/") {
+		candidates = []string{path.Join(p, "index.html")}
+	}
+
+	for _, name := range candidates {
+		data, modTime, ok := t.files.file(name)
+		if !ok {
+			continue
+		}
+
+		h := http.Header{}
+		ct := mime.TypeByExtension(path.Ext(name))
+		if ct == "" {
+			ct = http.DetectContentType(data)
+		}
+		h.Set("Content-Type", ct)
+		if !modTime.IsZero() {
+			h.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+			since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+			if err == nil && !modTime.Truncate(time.Second).After(since) {
+				return localResponse(req, http.StatusNotModified, h, nil), nil
+			}
+		}
+		if req.Method == http.MethodHead {
+			data = nil
+		}
+		return localResponse(req, http.StatusOK, h, data), nil
+	}
+	return localResponse(req, http.StatusNotFound, nil, []byte("404 page not found\n")), nil
+}
+
+// localResponse builds the response to a request served locally
+func localResponse(req *http.Request, status int, h http.Header, body []byte) *http.Response {
+	if h == nil {
+		h = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// roundTrip sends the request with the transport,
+// the default transport if there is none
+func roundTrip(t http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	return t.RoundTrip(req)
+}
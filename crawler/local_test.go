@@ -0,0 +1,174 @@
+package crawler
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-crawler/crawler/crawlertest"
+)
+
+// writeTestSite writes the test site as a directory, a zip
+// and a tar.gz archive into dir
+func writeTestSite(t *testing.T, dir string) (string, string, string) {
+	site := filepath.Join(dir, "site")
+	zf, _ := os.Create(filepath.Join(dir, "site.zip"))
+	zw := zip.NewWriter(zf)
+	tf, _ := os.Create(filepath.Join(dir, "site.tar.gz"))
+	gw := gzip.NewWriter(tf)
+	tw := tar.NewWriter(gw)
+
+	for name, data := range crawlertest.Files() {
+		file := filepath.Join(site, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		ioutil.WriteFile(file, data, 0644)
+
+		w, _ := zw.CreateHeader(&zip.FileHeader{Name: name, Modified: time.Now()})
+		w.Write(data)
+
+		tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()})
+		tw.Write(data)
+	}
+	zw.Close()
+	zf.Close()
+	tw.Close()
+	gw.Close()
+	tf.Close()
+	return site, zf.Name(), tf.Name()
+}
+
+func TestCreeper_crawl_local(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	site, zipFile, tarFile := writeTestSite(t, dir)
+
+	for _, source := range []string{site, zipFile, tarFile} {
+		t.Run(filepath.Base(source), func(t *testing.T) {
+			cc := newTestCreeper(testBaseURL)
+			cc.Source = source
+			if err := cc.clientSetup(); err != nil {
+				t.Fatalf("TestCreeper_crawl_local error = %v", err)
+			}
+			cc.crawl(cc.fetch())
+
+			if len(cc.seenLinks) != 6 {
+				t.Errorf("TestCreeper_crawl_local crawled %d pages, want 6", len(cc.seenLinks))
+			}
+			for u := range cc.seenLinks {
+				if cc.statuses[u] != http.StatusOK || cc.validators[u].LastModified == "" {
+					t.Errorf("TestCreeper_crawl_local %s status %d, validator %v", u, cc.statuses[u], cc.validators[u])
+				}
+			}
+		})
+	}
+}
+
+func TestCreeper_crawl_local_relative(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	site := map[string]string{
+		"index.html":      `<a href="docs/">Docs</a> <a href="about.html">About</a> <a href="logo.png">Logo</a>`,
+		"about.html":      `<a href="docs/intro.html">Introduction</a>`,
+		"docs/index.html": `<a href="intro.html">Introduction</a> <a href="../about.html">About</a>`,
+		"docs/intro.html": `<a href="../">Home</a> <a href="./">Docs</a>`,
+		"logo.png":        "PNG",
+	}
+	for name, content := range site {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cc := newTestCreeper(testBaseURL)
+	cc.Source = dir
+	if err := cc.clientSetup(); err != nil {
+		t.Fatalf("TestCreeper_crawl_local_relative error = %v", err)
+	}
+	cc.crawl(cc.fetch())
+
+	want := map[string][]string{
+		"https://mmmmm.com":                 {"https://mmmmm.com/docs/", "https://mmmmm.com/about.html"},
+		"https://mmmmm.com/docs/":           {"https://mmmmm.com/docs/intro.html", "https://mmmmm.com/about.html"},
+		"https://mmmmm.com/about.html":      {"https://mmmmm.com/docs/intro.html"},
+		"https://mmmmm.com/docs/intro.html": {"https://mmmmm.com/docs/"},
+	}
+	if !reflect.DeepEqual(cc.seenLinks, want) {
+		t.Errorf("TestCreeper_crawl_local_relative = %v, want %v", cc.seenLinks, want)
+	}
+}
+
+func TestLocalTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	site, _, _ := writeTestSite(t, dir)
+	files, _ := openSource(site)
+	lt := &localTransport{host: "mmmmm.com", files: files}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		since      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "root index", path: "/", wantStatus: http.StatusOK, wantBody: `href="/about"`},
+		{name: "directory index", path: "/about/", wantStatus: http.StatusOK, wantBody: `href="/careers"`},
+		{name: "directory without slash", path: "/info", wantStatus: http.StatusOK, wantBody: `href="/generic"`},
+		{name: "extension", path: "/faq", wantStatus: http.StatusOK, wantBody: `href="/info"`},
+		{name: "file", path: "/faq.html", wantStatus: http.StatusOK, wantBody: `href="/info"`},
+		{name: "no index", path: "/faq/", wantStatus: http.StatusNotFound},
+		{name: "outside the site", path: "/../site/faq.html", wantStatus: http.StatusNotFound},
+		{name: "missing", path: "/missing", wantStatus: http.StatusNotFound},
+		{name: "not modified", path: "/faq", since: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), wantStatus: http.StatusNotModified},
+		{name: "modified", path: "/faq", since: "Wed, 21 Oct 2015 07:28:00 GMT", wantStatus: http.StatusOK},
+		{name: "post", method: http.MethodPost, path: "/faq", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, _ := http.NewRequest(method, "https://mmmmm.com"+tt.path, nil)
+			if tt.since != "" {
+				req.Header.Set("If-Modified-Since", tt.since)
+			}
+			res, err := lt.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("TestLocalTransport error = %v", err)
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("TestLocalTransport = %d %q, want %d %q", res.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+			if res.StatusCode == http.StatusOK && res.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+				t.Errorf("TestLocalTransport Content-Type = %s", res.Header.Get("Content-Type"))
+			}
+		})
+	}
+
+	if _, err := openSource(filepath.Join(site, "faq.html")); err == nil || !strings.HasPrefix(err.Error(), ErrUnknownSource.Error()) {
+		t.Errorf("TestLocalTransport openSource error = %v, want %v", err, ErrUnknownSource)
+	}
+}
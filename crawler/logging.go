@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var (
+	ErrUnknownLogLevel = errors.New("Unknown log level, use debug, info, warn or error")
+)
+
+// NewLogger creates a structured logger writing to w at the given level,
+// debug, info (default), warn or error, in the given format, text
+// (default) or json
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+			return nil, ErrUnknownLogLevel
+		}
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch format {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// logger returns the logger of the crawler, the default logger
+// if none was set
+func (cc *Creeper) logger() *slog.Logger {
+	if cc.Logger != nil {
+		return cc.Logger
+	}
+	return slog.Default()
+}
+
+// status returns the recorded status code of a url, 0 if unknown
+func (cc *Creeper) status(url string) int {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	return cc.statuses[url]
+}
@@ -0,0 +1,100 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		format  string
+		wantErr error
+		want    string
+	}{
+		{
+			name: "defaults",
+			want: `level=INFO msg=shown depth=1`,
+		},
+		{
+			name:   "json",
+			level:  "debug",
+			format: FormatJSON,
+			want:   `"level":"DEBUG","msg":"hidden","depth":1}`,
+		},
+		{
+			name:  "warn",
+			level: "Warn",
+			want:  "",
+		},
+		{
+			name:    "unknown level",
+			level:   "verbose",
+			wantErr: ErrUnknownLogLevel,
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger, err := NewLogger(&out, tt.level, tt.format)
+			if err != tt.wantErr {
+				t.Fatalf("TestNewLogger error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			logger.Debug("hidden", "depth", 1)
+			logger.Info("shown", "depth", 1)
+
+			got := out.String()
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("TestNewLogger = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreeper_process_logging(t *testing.T) {
+	var out bytes.Buffer
+	logger, _ := NewLogger(&out, "debug", FormatJSON)
+
+	cc := &Creeper{
+		BaseURL: testBaseURL,
+		Depth:   int8(1),
+		Logger:  logger,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+
+	cc.process(1, "https://mmmmm.com/about", mockFetch(testBaseURL))
+	cc.process(1, "https://mmmmm.com/missing", func(string) (string, error) {
+		return "", errors.New("connection refused")
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("TestCreeper_process_logging logged %d lines, want 2:\n%s", len(lines), out.String())
+	}
+
+	var fetched, failed map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &fetched)
+	json.Unmarshal([]byte(lines[1]), &failed)
+
+	if fetched["level"] != "DEBUG" || fetched["msg"] != "page fetched" || fetched["url"] != "https://mmmmm.com/about" ||
+		fetched["depth"] != float64(1) || fetched["bytes"] == nil || fetched["duration"] == nil {
+		t.Errorf("TestCreeper_process_logging fetched = %v", fetched)
+	}
+	if failed["level"] != "WARN" || failed["msg"] != "page not fetched" || failed["url"] != "https://mmmmm.com/missing" ||
+		failed["status"] != float64(0) || failed["error"] != "connection refused" {
+		t.Errorf("TestCreeper_process_logging failed = %v", failed)
+	}
+}
@@ -0,0 +1,156 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchBuckets are the upper bounds, in seconds, of the fetch
+// latency histogram
+var fetchBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects metrics of crawls and serves them in the Prometheus
+// text format. One Metrics can be shared by several crawlers.
+type Metrics struct {
+	// requests by host and status class
+	requests map[requestKey]int64
+	// fetch latency histogram
+	bucketCounts []int64
+	latencySum   float64
+	latencyCount int64
+	frontier     int64
+	inFlight     int64
+	bytes        int64
+	retries      int64
+	mu           sync.Mutex
+}
+
+type requestKey struct {
+	host  string
+	class string
+}
+
+// NewMetrics creates an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:     map[requestKey]int64{},
+		bucketCounts: make([]int64, len(fetchBuckets)),
+	}
+}
+
+// statusClass groups status codes as 2xx, 3xx, 4xx and 5xx,
+// requests without response as error
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "error"
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
+
+// fetchStarted records a request in flight
+func (m *Metrics) fetchStarted() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight++
+}
+
+// fetchDone records a finished request, with its status code,
+// 0 when no response was received, its latency and size
+func (m *Metrics) fetchDone(host string, status int, latency time.Duration, n int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight--
+	m.requests[requestKey{host: host, class: statusClass(status)}]++
+	m.bytes += int64(n)
+
+	secs := latency.Seconds()
+	m.latencySum += secs
+	m.latencyCount++
+	for i, b := range fetchBuckets {
+		if secs <= b {
+			m.bucketCounts[i]++
+		}
+	}
+}
+
+// retried records a retried request
+func (m *Metrics) retried() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries++
+}
+
+// addFrontier changes the number of queued urls
+func (m *Metrics) addFrontier(n int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.frontier += int64(n)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, m.text())
+}
+
+func (m *Metrics) text() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP crawler_requests_total Page requests by host and status class.\n")
+	b.WriteString("# TYPE crawler_requests_total counter\n")
+	keys := []requestKey{}
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].host != keys[j].host {
+			return keys[i].host < keys[j].host
+		}
+		return keys[i].class < keys[j].class
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "crawler_requests_total{host=%q,class=%q} %d\n", k.host, k.class, m.requests[k])
+	}
+
+	b.WriteString("# HELP crawler_fetch_duration_seconds Latency of page fetches.\n")
+	b.WriteString("# TYPE crawler_fetch_duration_seconds histogram\n")
+	for i, bound := range fetchBuckets {
+		fmt.Fprintf(&b, "crawler_fetch_duration_seconds_bucket{le=\"%g\"} %d\n", bound, m.bucketCounts[i])
+	}
+	fmt.Fprintf(&b, "crawler_fetch_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.latencyCount)
+	fmt.Fprintf(&b, "crawler_fetch_duration_seconds_sum %g\n", m.latencySum)
+	fmt.Fprintf(&b, "crawler_fetch_duration_seconds_count %d\n", m.latencyCount)
+
+	metric := func(name, typ, help string, v int64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, typ, name, v)
+	}
+	metric("crawler_frontier_size", "gauge", "Urls queued at the current depth and not crawled yet.", m.frontier)
+	metric("crawler_in_flight_requests", "gauge", "Page requests in flight.", m.inFlight)
+	metric("crawler_downloaded_bytes_total", "counter", "Bytes of fetched pages.", m.bytes)
+	metric("crawler_retries_total", "counter", "Retried page requests.", m.retries)
+
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_text(t *testing.T) {
+	m := NewMetrics()
+	m.addFrontier(3)
+	for _, f := range []struct {
+		host    string
+		status  int
+		latency time.Duration
+		size    int
+	}{
+		{"mmmmm.com", 200, 30 * time.Millisecond, 1000},
+		{"mmmmm.com", 404, 200 * time.Millisecond, 0},
+		{"mmmmm.com", 200, 3 * time.Second, 500},
+		{"other.com", 0, 20 * time.Second, 0},
+	} {
+		m.fetchStarted()
+		m.fetchDone(f.host, f.status, f.latency, f.size)
+	}
+	m.fetchStarted()
+	m.addFrontier(-1)
+
+	want := `# HELP crawler_requests_total Page requests by host and status class.
+# TYPE crawler_requests_total counter
+crawler_requests_total{host="mmmmm.com",class="2xx"} 2
+crawler_requests_total{host="mmmmm.com",class="4xx"} 1
+crawler_requests_total{host="other.com",class="error"} 1
+# HELP crawler_fetch_duration_seconds Latency of page fetches.
+# TYPE crawler_fetch_duration_seconds histogram
+crawler_fetch_duration_seconds_bucket{le="0.05"} 1
+crawler_fetch_duration_seconds_bucket{le="0.1"} 1
+crawler_fetch_duration_seconds_bucket{le="0.25"} 2
+crawler_fetch_duration_seconds_bucket{le="0.5"} 2
+crawler_fetch_duration_seconds_bucket{le="1"} 2
+crawler_fetch_duration_seconds_bucket{le="2.5"} 2
+crawler_fetch_duration_seconds_bucket{le="5"} 3
+crawler_fetch_duration_seconds_bucket{le="10"} 3
+crawler_fetch_duration_seconds_bucket{le="+Inf"} 4
+crawler_fetch_duration_seconds_sum 23.23
+crawler_fetch_duration_seconds_count 4
+# HELP crawler_frontier_size Urls queued at the current depth and not crawled yet.
+# TYPE crawler_frontier_size gauge
+crawler_frontier_size 2
+# HELP crawler_in_flight_requests Page requests in flight.
+# TYPE crawler_in_flight_requests gauge
+crawler_in_flight_requests 1
+# HELP crawler_downloaded_bytes_total Bytes of fetched pages.
+# TYPE crawler_downloaded_bytes_total counter
+crawler_downloaded_bytes_total 1500
+# HELP crawler_retries_total Retried page requests.
+# TYPE crawler_retries_total counter
+crawler_retries_total 0
+`
+	if got := m.text(); got != want {
+		t.Errorf("TestMetrics_text = %s, want %s", got, want)
+	}
+}
+
+func TestCreeper_crawl_metrics(t *testing.T) {
+	ts := mockServer(nil)
+	defer ts.Close()
+
+	cc := newTestCreeper(ts.URL)
+	cc.Metrics = NewMetrics()
+	cc.crawl(cc.fetch())
+
+	rec := httptest.NewRecorder()
+	cc.Metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	got := rec.Body.String()
+
+	host := strings.TrimPrefix(ts.URL, "http://")
+	for _, want := range []string{
+		`crawler_requests_total{host="` + host + `",class="2xx"} 6`,
+		"crawler_fetch_duration_seconds_count 6",
+		"crawler_frontier_size 0",
+		"crawler_in_flight_requests 0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("TestCreeper_crawl_metrics metrics do not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCreeper_fetch_retries(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries int
+		wantErr bool
+		want    string
+	}{
+		{name: "no retries", retries: 0, wantErr: true, want: "crawler_retries_total 0"},
+		{name: "too few retries", retries: 1, wantErr: true, want: "crawler_retries_total 1"},
+		{name: "enough retries", retries: 2, want: "crawler_retries_total 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := 2
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if failures > 0 {
+					failures--
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte("<html></html>"))
+			}))
+			defer ts.Close()
+
+			cc := newTestCreeper(ts.URL)
+			cc.Metrics = NewMetrics()
+			cc.Retries = tt.retries
+			cc.RetryBackoff = time.Millisecond
+
+			body, err := cc.fetch()(ts.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TestCreeper_fetch_retries error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && body != "<html></html>" {
+				t.Errorf("TestCreeper_fetch_retries body = %q, want %q", body, "<html></html>")
+			}
+			if got := cc.Metrics.text(); !strings.Contains(got, tt.want) {
+				t.Errorf("TestCreeper_fetch_retries metrics do not contain %q:\n%s", tt.want, got)
+			}
+		})
+	}
+}
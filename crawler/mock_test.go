@@ -0,0 +1,58 @@
+package crawler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/tamarakaufler/go-crawler/crawler/crawlertest"
+)
+
+// mockFiles reads the mock site into memory
+func mockFiles() memFiles {
+	files := memFiles{}
+	for name, data := range crawlertest.Files() {
+		files[name] = memFile{data: data}
+	}
+	return files
+}
+
+// mockServer serves the mock site with an ETag per page
+// and answers conditional requests
+func mockServer(etags map[string]string) *httptest.Server {
+	return httptest.NewServer(mockHandler(etags))
+}
+
+func mockHandler(etags map[string]string) http.HandlerFunc {
+	return crawlertest.Handler(etags)
+}
+
+// mock fetch serving the mock site at the test base, as a crawl
+// of a local source does
+func mockFetch(testBase string) func(string) (string, error) {
+	base, _ := url.Parse(testBase)
+	client := &http.Client{
+		Transport: &localTransport{host: base.Host, files: mockFiles()},
+	}
+
+	return func(u string) (string, error) {
+		if parsed, err := url.Parse(u); err != nil || parsed.Host != base.Host {
+			return "", fmt.Errorf("%s: not in the mock site", u)
+		}
+		res, err := client.Get(u)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s: status %d", u, res.StatusCode)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	}
+}
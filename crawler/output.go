@@ -0,0 +1,215 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	ErrUnknownFormat = errors.New("Unknown output format")
+)
+
+// Result is the outcome of a crawl, as written in the JSON format
+type Result struct {
+	BaseURL     string              `json:"base_url"`
+	EndReason   string              `json:"end_reason"`
+	Pages       []PageResult        `json:"pages"`
+	BrokenLinks []BrokenLink        `json:"broken_links,omitempty"`
+	Changes     map[string][]string `json:"changes,omitempty"`
+	Analysis    *LinkAnalysis       `json:"analysis,omitempty"`
+	Duplicates  *DuplicateReport    `json:"duplicates,omitempty"`
+	SEO         *SEOAudit           `json:"seo,omitempty"`
+	Assets      *AssetReport        `json:"assets,omitempty"`
+	Anchors     *AnchorReport       `json:"anchors,omitempty"`
+	Fragments   *FragmentReport     `json:"fragments,omitempty"`
+	Cookies     *CookieReport       `json:"cookies,omitempty"`
+}
+
+// PageResult is a crawled page with its links and scores
+type PageResult struct {
+	URL       string      `json:"url"`
+	Depth     int8        `json:"depth"`
+	Status    int         `json:"status,omitempty"`
+	Links     []string    `json:"links"`
+	PageRank  float64     `json:"pagerank,omitempty"`
+	Hub       float64     `json:"hub,omitempty"`
+	Authority float64     `json:"authority,omitempty"`
+	Meta      *PageMeta   `json:"meta,omitempty"`
+	Assets    []PageAsset `json:"assets,omitempty"`
+	// LinkContexts describe the occurrences of the links on the page
+	LinkContexts []LinkContext `json:"link_contexts,omitempty"`
+}
+
+// PageAsset is an asset of a page with its status code
+type PageAsset struct {
+	Asset
+	Status int `json:"status"`
+}
+
+// checkFormat checks the requested output format
+func checkFormat(format string) error {
+	switch format {
+	case "", FormatText, FormatJSON:
+		return nil
+	}
+	return ErrUnknownFormat
+}
+
+// Output writes the sitemap and the enabled reports of a finished crawl
+// in the given format
+func (cc *Creeper) Output(w io.Writer, format string) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	cc.muOutput.Lock()
+	defer cc.muOutput.Unlock()
+
+	out, f := cc.Out, cc.Format
+	cc.Out, cc.Format = w, format
+	defer func() {
+		cc.Out, cc.Format = out, f
+	}()
+
+	return cc.output(cc.crawlTime())
+}
+
+// Result returns the crawled pages, in breadth-first order,
+// with the reports enabled for the crawler
+func (cc *Creeper) Result() *Result {
+	r := &Result{
+		BaseURL:   cc.BaseURL,
+		EndReason: cc.endReason(),
+		Pages:     []PageResult{},
+	}
+
+	var scores map[string]PageScore
+	if cc.Ranking {
+		scores = map[string]PageScore{}
+		for _, s := range cc.Rank(cc.Damping, cc.RankIterations, cc.HITS) {
+			scores[s.URL] = s
+		}
+	}
+	if cc.previous != nil {
+		r.Changes = cc.pageChanges()
+	}
+	if cc.Analysis {
+		r.Analysis = cc.AnalyzeLinks(cc.AnalysisTop, cc.AnalysisMaxLinks)
+	}
+	if cc.Duplicates {
+		r.Duplicates = cc.FindDuplicates(cc.Similarity)
+	}
+	if cc.SEO {
+		r.SEO = cc.AuditSEO()
+	}
+	if cc.Assets {
+		r.Assets = cc.ReportAssets()
+	}
+	if cc.LinkContext {
+		r.Anchors = cc.ReportAnchors()
+	}
+	if cc.Fragments {
+		r.Fragments = cc.CheckFragments()
+	}
+	if cc.CookieReport {
+		r.Cookies = cc.ReportCookies()
+	}
+	if broken := brokenLinks(cc.snapshot()); len(broken) > 0 {
+		r.BrokenLinks = broken
+	}
+
+	cc.muSeen.Lock()
+	for _, u := range cc.pageOrder() {
+		var meta *PageMeta
+		if m, ok := cc.metadata[u]; ok {
+			meta = &m
+		}
+		var assets []PageAsset
+		for _, a := range cc.assets[u] {
+			assets = append(assets, PageAsset{Asset: a, Status: cc.assetStatuses[a.URL]})
+		}
+		r.Pages = append(r.Pages, PageResult{
+			URL:          u,
+			Depth:        cc.seenDepths[u],
+			Status:       cc.statuses[u],
+			Links:        cc.seenLinks[u],
+			PageRank:     scores[u].PageRank,
+			Hub:          scores[u].Hub,
+			Authority:    scores[u].Authority,
+			Meta:         meta,
+			Assets:       assets,
+			LinkContexts: cc.linkContexts[u],
+		})
+	}
+	cc.muSeen.Unlock()
+
+	return r
+}
+
+// pageOrder returns the crawled pages sorted by depth and url.
+// muSeen must be held
+func (cc *Creeper) pageOrder() []string {
+	pages := sortedPages(cc.seenLinks)
+	sort.SliceStable(pages, func(i, j int) bool {
+		return cc.seenDepths[pages[i]] < cc.seenDepths[pages[j]]
+	})
+	return pages
+}
+
+// output writes the crawl outcome in the requested format
+func (cc *Creeper) output(elapsed time.Duration) error {
+	if cc.Format == FormatJSON {
+		enc := json.NewEncoder(cc.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cc.Result())
+	}
+
+	cc.muSeen.Lock()
+	cc.display(cc.Depth, "   ")
+	cc.muSeen.Unlock()
+
+	if cc.previous != nil {
+		cc.displayChanges()
+	}
+	if cc.Analysis {
+		cc.AnalyzeLinks(cc.AnalysisTop, cc.AnalysisMaxLinks).Display(cc.Out)
+	}
+	if cc.Duplicates {
+		cc.FindDuplicates(cc.Similarity).Display(cc.Out)
+	}
+	if cc.SEO {
+		cc.AuditSEO().Display(cc.Out)
+	}
+	if cc.Assets {
+		cc.ReportAssets().Display(cc.Out)
+	}
+	if cc.LinkContext {
+		cc.ReportAnchors().Display(cc.Out)
+	}
+	if cc.Fragments {
+		cc.CheckFragments().Display(cc.Out)
+	}
+	if cc.CookieReport {
+		cc.ReportCookies().Display(cc.Out)
+	}
+	if cc.Ranking {
+		displayRanking(cc.Out, cc.Rank(cc.Damping, cc.RankIterations, cc.HITS), cc.HITS)
+	}
+	fmt.Fprintf(cc.Out, ">> The crawl ended: %s <<\n", cc.endReason())
+	if !cc.Deterministic {
+		fmt.Fprintf(cc.Out, ">> The crawler took %s <<\n", elapsed)
+	}
+	fmt.Fprintln(cc.Out)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCreeper_output_json(t *testing.T) {
+	out := &bytes.Buffer{}
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(2),
+		Deterministic: true,
+		Format:        FormatJSON,
+		Ranking:       true,
+		Out:           out,
+	}
+	if err := inputCheck(cc); err != nil {
+		t.Fatal(err)
+	}
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	if err := cc.output(0); err != nil {
+		t.Fatalf("TestCreeper_output_json error = %v", err)
+	}
+
+	got := &Result{}
+	if err := json.Unmarshal(out.Bytes(), got); err != nil {
+		t.Fatalf("TestCreeper_output_json invalid json: %v\n%s", err, out.String())
+	}
+	if got.EndReason != EndCompleted {
+		t.Errorf("TestCreeper_output_json end reason = %q, want %q", got.EndReason, EndCompleted)
+	}
+
+	pages := []string{}
+	for _, p := range got.Pages {
+		pages = append(pages, p.URL)
+		if p.PageRank <= 0 {
+			t.Errorf("TestCreeper_output_json page %s has no pagerank", p.URL)
+		}
+	}
+	wantPages := []string{
+		"https://mmmmm.com",
+		"https://mmmmm.com/about",
+		"https://mmmmm.com/faq",
+		"https://mmmmm.com/careers",
+		"https://mmmmm.com/info",
+	}
+	if !reflect.DeepEqual(pages, wantPages) {
+		t.Errorf("TestCreeper_output_json pages = %v, want %v", pages, wantPages)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, f := range []string{"", FormatText, FormatJSON} {
+		if err := checkFormat(f); err != nil {
+			t.Errorf("TestCheckFormat(%q) error = %v", f, err)
+		}
+	}
+	if err := checkFormat("yaml"); err != ErrUnknownFormat {
+		t.Errorf("TestCheckFormat(yaml) error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
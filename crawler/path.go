@@ -0,0 +1,124 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const defaultMaxPaths = 10
+
+var (
+	ErrPageNotCrawled = errors.New("Page not found in the crawl")
+)
+
+// ShortestPaths returns up to max shortest click paths from one
+// crawled page to another. Paths are listed in link order.
+// An empty from means the base URL.
+func (cc *Creeper) ShortestPaths(from, to string, max int) ([][]string, error) {
+	cc.muSeen.Lock()
+	defer cc.muSeen.Unlock()
+
+	return shortestPaths(cc.BaseURL, cc.seenLinks, from, to, max)
+}
+
+// PathsInCrawl returns up to max shortest click paths between pages
+// of a saved crawl, given as a state directory or a state file.
+// Pages can be given as urls or as paths of the crawled site.
+func PathsInCrawl(crawlPath, from, to string, max int) ([][]string, error) {
+	st, err := loadCrawl(crawlPath)
+	if err != nil {
+		return nil, err
+	}
+	return shortestPaths(st.BaseURL, st.Links, from, to, max)
+}
+
+func shortestPaths(baseURL string, links map[string][]string, from, to string, max int) ([][]string, error) {
+	if max <= 0 {
+		max = defaultMaxPaths
+	}
+	from = pageURL(baseURL, from)
+	to = pageURL(baseURL, to)
+
+	if _, ok := links[from]; !ok {
+		return nil, fmt.Errorf("%v: %s", ErrPageNotCrawled, from)
+	}
+	if from == to {
+		return [][]string{{from}}, nil
+	}
+
+	g := newLinkGraph(links)
+
+	// breadth-first search, remembering all predecessors
+	// on the shortest paths
+	dist := map[string]int{from: 0}
+	preds := map[string][]string{}
+	queue := []string{from}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if _, ok := dist[to]; ok && dist[u] >= dist[to] {
+			break
+		}
+		for _, v := range g.out[u] {
+			d, seen := dist[v]
+			if !seen {
+				dist[v] = dist[u] + 1
+				queue = append(queue, v)
+			} else if d != dist[u]+1 {
+				continue
+			}
+			preds[v] = append(preds[v], u)
+		}
+	}
+	if _, ok := dist[to]; !ok {
+		return [][]string{}, nil
+	}
+
+	// walk the predecessors back from the target
+	paths := [][]string{}
+	var walk func(u string, suffix []string)
+	walk = func(u string, suffix []string) {
+		if len(paths) >= max {
+			return
+		}
+		path := append([]string{u}, suffix...)
+		if u == from {
+			paths = append(paths, path)
+			return
+		}
+		for _, p := range preds[u] {
+			walk(p, path)
+		}
+	}
+	walk(to, nil)
+
+	return paths, nil
+}
+
+// pageURL turns a path of the crawled site into a url
+func pageURL(baseURL, page string) string {
+	if page == "" || page == "/" {
+		return baseURL
+	}
+	if strings.HasPrefix(page, "/") {
+		return baseURL + page
+	}
+	return page
+}
+
+// DisplayPaths displays click paths
+func DisplayPaths(w io.Writer, paths [][]string) {
+	fmt.Fprint(w, "👍 Shortest click paths 👍\n\n")
+	if len(paths) == 0 {
+		fmt.Fprintln(w, "   no path found")
+	}
+	for i, p := range paths {
+		fmt.Fprintf(w, "   - %d - %d clicks\n", i, len(p)-1)
+		for depth, u := range p {
+			fmt.Fprintf(w, "%s* %s\n", createOffset("   ", int8(depth+1)), u)
+		}
+	}
+	fmt.Fprintln(w, "\n👍 The END 👍")
+}
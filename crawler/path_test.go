@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCreeper_ShortestPaths(t *testing.T) {
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		max     int
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "all shortest paths from the base URL",
+			to:   "https://mmmmm.com/generic",
+			want: [][]string{
+				{"https://mmmmm.com", "https://mmmmm.com/faq", "https://mmmmm.com/info", "https://mmmmm.com/generic"},
+				{"https://mmmmm.com", "https://mmmmm.com/about", "https://mmmmm.com/careers", "https://mmmmm.com/generic"},
+			},
+		},
+		{
+			name: "limited number of paths",
+			to:   "/generic",
+			max:  1,
+			want: [][]string{
+				{"https://mmmmm.com", "https://mmmmm.com/faq", "https://mmmmm.com/info", "https://mmmmm.com/generic"},
+			},
+		},
+		{
+			name: "paths from another page",
+			from: "/faq",
+			to:   "/careers",
+			want: [][]string{
+				{"https://mmmmm.com/faq", "https://mmmmm.com/about", "https://mmmmm.com/careers"},
+			},
+		},
+		{
+			name: "no path",
+			from: "/generic",
+			to:   "/faq",
+			want: [][]string{},
+		},
+		{
+			name: "same page",
+			from: "/faq",
+			to:   "https://mmmmm.com/faq",
+			want: [][]string{{"https://mmmmm.com/faq"}},
+		},
+		{
+			name:    "page not crawled",
+			from:    "/nowhere",
+			to:      "/faq",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cc.ShortestPaths(tt.from, tt.to, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TestCreeper_ShortestPaths error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TestCreeper_ShortestPaths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathsInCrawl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crawler-path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+		StateDir:      dir,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+	cc.crawl(mockFetch(testBaseURL))
+
+	got, err := PathsInCrawl(dir, "", "/info", 0)
+	if err != nil {
+		t.Fatalf("TestPathsInCrawl error = %v", err)
+	}
+	want := [][]string{{"https://mmmmm.com", "https://mmmmm.com/faq", "https://mmmmm.com/info"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestPathsInCrawl = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,246 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	defaultProgressInterval = 10 * time.Second
+	progressRefresh         = 100 * time.Millisecond
+)
+
+// Progress is a snapshot of a running or finished crawl
+type Progress struct {
+	// Depth is the level being crawled
+	Depth        int8  `json:"depth"`
+	PagesFetched int   `json:"pages_fetched"`
+	PagesFailed  int   `json:"pages_failed"`
+	BytesFetched int64 `json:"bytes_fetched"`
+	// Queued are pages of the current level not processed yet
+	Queued            int           `json:"queued"`
+	RequestsPerSecond float64       `json:"requests_per_second"`
+	Elapsed           time.Duration `json:"elapsed"`
+	// ETA is the estimated time until the first budget runs out,
+	// 0 when no budget is set
+	ETA time.Duration `json:"eta,omitempty"`
+	// EndReason is empty while the crawl runs
+	EndReason string `json:"end_reason,omitempty"`
+}
+
+// Progress returns how far the crawl got
+func (cc *Creeper) Progress() Progress {
+	cc.muSeen.Lock()
+	p := Progress{
+		Depth:  cc.level,
+		Queued: len(cc.queued) - cc.processed,
+	}
+	cc.muSeen.Unlock()
+	// pages beyond the depth are queued, but never crawled
+	if p.Depth > cc.Depth {
+		p.Depth, p.Queued = cc.Depth, 0
+	}
+	if p.Queued < 0 {
+		p.Queued = 0
+	}
+
+	p.Elapsed = cc.crawlTime()
+
+	cc.muBudget.Lock()
+	p.PagesFetched = cc.pagesFetched
+	p.PagesFailed = cc.pagesFailed
+	p.BytesFetched = cc.bytesFetched
+	p.EndReason = cc.EndReason
+	cc.muBudget.Unlock()
+
+	if secs := p.Elapsed.Seconds(); secs > 0 {
+		p.RequestsPerSecond = float64(p.PagesFetched) / secs
+	}
+	p.ETA = cc.eta(p)
+
+	return p
+}
+
+// eta estimates, from the rate of the crawl so far, when the first
+// of the budgets runs out
+func (cc *Creeper) eta(p Progress) time.Duration {
+	var eta time.Duration
+	earliest := func(d time.Duration) {
+		if d < 0 {
+			d = 0
+		}
+		if eta == 0 || d < eta {
+			eta = d
+		}
+	}
+
+	if cc.MaxDuration > 0 {
+		earliest(cc.MaxDuration - p.Elapsed)
+	}
+	if cc.MaxPages > 0 && p.PagesFetched > 0 {
+		earliest(time.Duration(float64(p.Elapsed) * float64(cc.MaxPages-p.PagesFetched) / float64(p.PagesFetched)))
+	}
+	if cc.MaxBytes > 0 && p.BytesFetched > 0 {
+		earliest(time.Duration(float64(p.Elapsed) * float64(cc.MaxBytes-p.BytesFetched) / float64(p.BytesFetched)))
+	}
+	return eta
+}
+
+// String renders the progress on a single line
+func (p Progress) String() string {
+	s := fmt.Sprintf("depth %d | fetched %d | queued %d | failed %d | %s | %.1f req/s | %s",
+		p.Depth, p.PagesFetched, p.Queued, p.PagesFailed, byteCount(p.BytesFetched),
+		p.RequestsPerSecond, p.Elapsed.Round(time.Second))
+	if p.ETA > 0 {
+		s += fmt.Sprintf(" | ETA %s", p.ETA.Round(time.Second))
+	}
+	return s
+}
+
+func byteCount(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// notify tells the progress reporter something happened in the crawl,
+// without waiting for it
+func (cc *Creeper) notify() {
+	select {
+	case cc.events <- struct{}{}:
+	default:
+	}
+}
+
+// reportProgress displays the progress of the crawl until stopped.
+// If ProgressOut (default stderr) is a terminal, a single line is refreshed
+// on it as the crawl goes on, otherwise the progress is logged every
+// ProgressInterval (default 10s).
+func (cc *Creeper) reportProgress() (stop func()) {
+	var w io.Writer = os.Stderr
+	if cc.ProgressOut != nil {
+		w = cc.ProgressOut
+	}
+	if f, ok := w.(*os.File); ok && isTerminal(f) {
+		return cc.refreshProgress(f)
+	}
+
+	interval := cc.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	return cc.logProgress(interval)
+}
+
+// refreshProgress redraws the progress line on crawl events
+func (cc *Creeper) refreshProgress(w io.Writer) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(progressRefresh)
+		defer ticker.Stop()
+
+		width := 0
+		draw := func() {
+			line := cc.Progress().String()
+			pad := width - len(line)
+			if pad < 0 {
+				pad = 0
+			}
+			width = len(line)
+			fmt.Fprintf(w, "\r%s%s", line, strings.Repeat(" ", pad))
+		}
+
+		changed := false
+		for {
+			select {
+			case <-done:
+				draw()
+				fmt.Fprintln(w)
+				return
+			case <-cc.events:
+				changed = true
+			case <-ticker.C:
+				// redraw at most every progressRefresh
+				if changed {
+					draw()
+					changed = false
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// logProgress logs the progress periodically, while the crawl goes on
+func (cc *Creeper) logProgress(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		changed := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-cc.events:
+				changed = true
+			case <-ticker.C:
+				if changed {
+					p := cc.Progress()
+					cc.logger().Info("progress",
+						"depth", p.Depth,
+						"fetched", p.PagesFetched,
+						"queued", p.Queued,
+						"failed", p.PagesFailed,
+						"bytes", p.BytesFetched,
+						"requests_per_second", p.RequestsPerSecond,
+						"elapsed", p.Elapsed,
+						"eta", p.ETA,
+					)
+					changed = false
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// isTerminal returns true if the file is a terminal, not merely
+// a character device like /dev/null
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,182 @@
+package crawler
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreeper_Progress(t *testing.T) {
+	cc := &Creeper{
+		BaseURL:       testBaseURL,
+		Depth:         int8(5),
+		Deterministic: true,
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+
+	fetch := mockFetch(testBaseURL)
+	cc.crawl(func(url string) (string, error) {
+		if strings.HasSuffix(url, "/careers") {
+			return "", errors.New("careers not found")
+		}
+		return fetch(url)
+	})
+
+	got := cc.Progress()
+	if got.PagesFetched != 6 || got.PagesFailed != 1 || got.Queued != 0 || got.EndReason != EndCompleted {
+		t.Errorf("TestCreeper_Progress = %+v, want 6 pages fetched, 1 failed, none queued, completed", got)
+	}
+	if got.BytesFetched == 0 || got.RequestsPerSecond == 0 {
+		t.Errorf("TestCreeper_Progress = %+v, want bytes and requests per second", got)
+	}
+}
+
+func TestCreeper_eta(t *testing.T) {
+	type fields struct {
+		MaxDuration time.Duration
+		MaxPages    int
+		MaxBytes    int64
+	}
+	progress := Progress{
+		PagesFetched: 10,
+		BytesFetched: 1000,
+		Elapsed:      10 * time.Second,
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   time.Duration
+	}{
+		{
+			name:   "no budget",
+			fields: fields{},
+			want:   0,
+		},
+		{
+			name:   "time budget",
+			fields: fields{MaxDuration: time.Minute},
+			want:   50 * time.Second,
+		},
+		{
+			name:   "page budget",
+			fields: fields{MaxPages: 40},
+			want:   30 * time.Second,
+		},
+		{
+			name:   "earliest budget",
+			fields: fields{MaxDuration: time.Minute, MaxPages: 40, MaxBytes: 1500},
+			want:   5 * time.Second,
+		},
+		{
+			name:   "exhausted budget",
+			fields: fields{MaxPages: 10},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &Creeper{
+				MaxDuration: tt.fields.MaxDuration,
+				MaxPages:    tt.fields.MaxPages,
+				MaxBytes:    tt.fields.MaxBytes,
+			}
+			if got := cc.eta(progress); got != tt.want {
+				t.Errorf("TestCreeper_eta = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgress_String(t *testing.T) {
+	p := Progress{
+		Depth:             2,
+		PagesFetched:      30,
+		PagesFailed:       1,
+		BytesFetched:      3 * 1024 * 1024,
+		Queued:            12,
+		RequestsPerSecond: 2.5,
+		Elapsed:           12 * time.Second,
+		ETA:               90 * time.Second,
+	}
+	want := "depth 2 | fetched 30 | queued 12 | failed 1 | 3.0 MiB | 2.5 req/s | 12s | ETA 1m30s"
+	if got := p.String(); got != want {
+		t.Errorf("TestProgress_String = %q, want %q", got, want)
+	}
+}
+
+func TestCreeper_refreshProgress(t *testing.T) {
+	cc := &Creeper{
+		BaseURL: testBaseURL,
+		Depth:   int8(1),
+	}
+	inputCheck(cc)
+	crawlerInit(cc)
+
+	var out bytes.Buffer
+	stop := cc.refreshProgress(&out)
+	cc.crawl(mockFetch(testBaseURL))
+	stop()
+	stop()
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\r")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if !strings.HasPrefix(last, "depth 1 | fetched 3 | queued 0 | failed 0") {
+		t.Errorf("TestCreeper_refreshProgress last line = %q", last)
+	}
+}
+
+func TestCreeper_reportProgress(t *testing.T) {
+	var out, progress, logs bytes.Buffer
+	cc := &Creeper{
+		BaseURL:          testBaseURL,
+		Depth:            int8(1),
+		Out:              &out,
+		ProgressOut:      &progress,
+		ProgressInterval: time.Millisecond,
+	}
+	cc.Logger, _ = NewLogger(&logs, "info", FormatText)
+	inputCheck(cc)
+	crawlerInit(cc)
+
+	stop := cc.reportProgress()
+	fetch := mockFetch(testBaseURL)
+	cc.crawl(func(url string) (string, error) {
+		time.Sleep(5 * time.Millisecond)
+		return fetch(url)
+	})
+	stop()
+
+	// the progress is logged, as it is not drawn on a terminal,
+	// and stays out of the output
+	if out.Len() != 0 || progress.Len() != 0 {
+		t.Errorf("TestCreeper_reportProgress output = %q, progress = %q, want none", out.String(), progress.String())
+	}
+	if !strings.Contains(logs.String(), "msg=progress") {
+		t.Errorf("TestCreeper_reportProgress logs = %q, want the progress", logs.String())
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	file, err := ioutil.TempFile("", "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	for _, f := range []*os.File{devNull, file} {
+		if isTerminal(f) {
+			t.Errorf("TestIsTerminal(%s) = true, want false", f.Name())
+		}
+	}
+}
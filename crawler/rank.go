@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	defaultDamping        = 0.85
+	defaultRankIterations = 50
+)
+
+// PageScore holds link based scores of a crawled page. Its score is
+// the PageRank, hub and authority scores are set by HITS.
+type PageScore struct {
+	URL       string  `json:"url"`
+	PageRank  float64 `json:"pagerank"`
+	Hub       float64 `json:"hub,omitempty"`
+	Authority float64 `json:"authority,omitempty"`
+}
+
+// Rank scores the crawled pages by PageRank, with the given damping
+// factor and number of iterations, and optionally by HITS hub and
+// authority scores. Only links between crawled pages are considered.
+// Pages are returned from the highest PageRank.
+func (cc *Creeper) Rank(damping float64, iterations int, hits bool) []PageScore {
+	if damping <= 0 || damping >= 1 {
+		damping = defaultDamping
+	}
+	if iterations <= 0 {
+		iterations = defaultRankIterations
+	}
+
+	g := cc.graph().crawledOnly()
+	pr := g.pageRank(damping, iterations)
+
+	var hub, auth map[string]float64
+	if hits {
+		hub, auth = g.hits(iterations)
+	}
+
+	scores := []PageScore{}
+	for _, u := range g.nodes {
+		scores = append(scores, PageScore{
+			URL:       u,
+			PageRank:  pr[u],
+			Hub:       hub[u],
+			Authority: auth[u],
+		})
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].PageRank > scores[j].PageRank
+	})
+	return scores
+}
+
+// crawledOnly returns the graph without links to pages,
+// which were not crawled
+func (g *linkGraph) crawledOnly() *linkGraph {
+	c := &linkGraph{
+		nodes: g.nodes,
+		out:   map[string][]string{},
+		in:    map[string][]string{},
+	}
+	for _, from := range g.nodes {
+		c.out[from] = []string{}
+		for _, to := range g.out[from] {
+			if _, ok := g.out[to]; !ok {
+				continue
+			}
+			c.out[from] = append(c.out[from], to)
+			c.in[to] = append(c.in[to], from)
+		}
+	}
+	return c
+}
+
+// pageRank computes PageRank by power iteration. Rank of pages
+// without links is spread evenly across all pages.
+func (g *linkGraph) pageRank(damping float64, iterations int) map[string]float64 {
+	n := float64(len(g.nodes))
+	pr := map[string]float64{}
+	if n == 0 {
+		return pr
+	}
+	for _, u := range g.nodes {
+		pr[u] = 1 / n
+	}
+
+	for i := 0; i < iterations; i++ {
+		dangling := 0.0
+		for _, u := range g.nodes {
+			if len(g.out[u]) == 0 {
+				dangling += pr[u]
+			}
+		}
+
+		next := map[string]float64{}
+		for _, u := range g.nodes {
+			rank := (1-damping)/n + damping*dangling/n
+			for _, from := range g.in[u] {
+				rank += damping * pr[from] / float64(len(g.out[from]))
+			}
+			next[u] = rank
+		}
+		pr = next
+	}
+	return pr
+}
+
+// hits computes HITS hub and authority scores,
+// normalised to the unit length
+func (g *linkGraph) hits(iterations int) (map[string]float64, map[string]float64) {
+	hub := map[string]float64{}
+	auth := map[string]float64{}
+	for _, u := range g.nodes {
+		hub[u] = 1
+		auth[u] = 1
+	}
+
+	for i := 0; i < iterations; i++ {
+		for _, u := range g.nodes {
+			a := 0.0
+			for _, from := range g.in[u] {
+				a += hub[from]
+			}
+			auth[u] = a
+		}
+		normalise(auth)
+
+		for _, u := range g.nodes {
+			h := 0.0
+			for _, to := range g.out[u] {
+				h += auth[to]
+			}
+			hub[u] = h
+		}
+		normalise(hub)
+	}
+	return hub, auth
+}
+
+func normalise(scores map[string]float64) {
+	sum := 0.0
+	for _, s := range scores {
+		sum += s * s
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for u := range scores {
+		scores[u] /= norm
+	}
+}
+
+// displayRanking displays pages ranked by their scores
+func displayRanking(w io.Writer, scores []PageScore, hits bool) {
+	fmt.Fprint(w, "\n👍 Page ranking 👍\n\n")
+	for i, s := range scores {
+		if hits {
+			fmt.Fprintf(w, "   - %d - [%s] pagerank %.4f, hub %.4f, authority %.4f\n", i, s.URL, s.PageRank, s.Hub, s.Authority)
+			continue
+		}
+		fmt.Fprintf(w, "   - %d - [%s] pagerank %.4f\n", i, s.URL, s.PageRank)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCreeper_Rank(t *testing.T) {
+	cc := &Creeper{
+		BaseURL: "https://mmmmm.com",
+		seenLinks: map[string][]string{
+			"https://mmmmm.com":   []string{"https://mmmmm.com/a", "https://mmmmm.com/b"},
+			"https://mmmmm.com/a": []string{"https://mmmmm.com/b", "https://mmmmm.com/x"},
+			"https://mmmmm.com/b": []string{"https://mmmmm.com/c"},
+			"https://mmmmm.com/c": []string{"https://mmmmm.com/b"},
+		},
+	}
+
+	scores := cc.Rank(0.85, 100, true)
+	if len(scores) != 4 {
+		t.Fatalf("TestCreeper_Rank number of scores = %d, want 4", len(scores))
+	}
+
+	sum := 0.0
+	for _, s := range scores {
+		sum += s.PageRank
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("TestCreeper_Rank sum of pageranks = %f, want 1", sum)
+	}
+	if scores[0].URL != "https://mmmmm.com/b" {
+		t.Errorf("TestCreeper_Rank top page = %s, want https://mmmmm.com/b", scores[0].URL)
+	}
+	for i := 1; i < len(scores); i++ {
+		if scores[i].PageRank > scores[i-1].PageRank {
+			t.Errorf("TestCreeper_Rank scores not sorted: %+v", scores)
+		}
+	}
+
+	byURL := map[string]PageScore{}
+	for _, s := range scores {
+		byURL[s.URL] = s
+	}
+	if byURL["https://mmmmm.com"].Authority != 0 {
+		t.Errorf("TestCreeper_Rank authority of the base URL = %f, want 0", byURL["https://mmmmm.com"].Authority)
+	}
+	if byURL["https://mmmmm.com/b"].Authority <= byURL["https://mmmmm.com/a"].Authority {
+		t.Errorf("TestCreeper_Rank authority of b = %f, want more than a = %f", byURL["https://mmmmm.com/b"].Authority, byURL["https://mmmmm.com/a"].Authority)
+	}
+	if byURL["https://mmmmm.com"].Hub <= byURL["https://mmmmm.com/c"].Hub {
+		t.Errorf("TestCreeper_Rank hub of the base URL = %f, want more than c = %f", byURL["https://mmmmm.com"].Hub, byURL["https://mmmmm.com/c"].Hub)
+	}
+}
+
+func TestCreeper_Rank_empty(t *testing.T) {
+	cc := &Creeper{
+		seenLinks: map[string][]string{},
+	}
+	if scores := cc.Rank(0, 0, true); len(scores) != 0 {
+		t.Errorf("TestCreeper_Rank_empty = %+v, want no scores", scores)
+	}
+}
+
+func TestPageScore_json(t *testing.T) {
+	tests := []struct {
+		name  string
+		score PageScore
+		want  string
+	}{
+		{
+			name:  "pagerank",
+			score: PageScore{URL: "https://mmmmm.com", PageRank: 0.5},
+			want:  `{"url":"https://mmmmm.com","pagerank":0.5}`,
+		},
+		{
+			name:  "hits",
+			score: PageScore{URL: "https://mmmmm.com/a", PageRank: 0.25, Hub: 0.5, Authority: 0.75},
+			want:  `{"url":"https://mmmmm.com/a","pagerank":0.25,"hub":0.5,"authority":0.75}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.score)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("PageScore json = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxTitleLength = 60
+
+// PageMeta is the on-page metadata of a crawled page
+type PageMeta struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	H1          []string `json:"h1"`
+	Canonical   string   `json:"canonical,omitempty"`
+	Lang        string   `json:"lang,omitempty"`
+	// Hreflang maps languages to urls of alternate pages
+	Hreflang map[string]string `json:"hreflang,omitempty"`
+}
+
+// SEOAudit lists metadata issues of the crawled pages
+type SEOAudit struct {
+	MissingTitles         []string            `json:"missing_titles"`
+	DuplicateTitles       map[string][]string `json:"duplicate_titles"`
+	LongTitles            []string            `json:"long_titles"`
+	MissingDescriptions   []string            `json:"missing_descriptions"`
+	DuplicateDescriptions map[string][]string `json:"duplicate_descriptions"`
+	MissingH1             []string            `json:"missing_h1"`
+	MultipleH1            []string            `json:"multiple_h1"`
+	HreflangNotReturned   []HreflangLink      `json:"hreflang_not_returned"`
+}
+
+// HreflangLink is an alternate language version of a page
+type HreflangLink struct {
+	From string `json:"from"`
+	Lang string `json:"lang"`
+	To   string `json:"to"`
+}
+
+// extractMeta extracts the metadata of a page
+func extractMeta(pageURL, body string) PageMeta {
+	m := PageMeta{
+		H1: elementTexts(body, "h1"),
+	}
+
+	if titles := elementTexts(body, "title"); len(titles) > 0 {
+		m.Title = titles[0]
+	}
+	if tags := findTags(body, "html"); len(tags) > 0 {
+		m.Lang = tags[0].attrs["lang"]
+	}
+	for _, t := range findTags(body, "meta") {
+		if strings.EqualFold(t.attrs["name"], "description") && m.Description == "" {
+			m.Description = strings.Join(strings.Fields(t.attrs["content"]), " ")
+		}
+	}
+	for _, t := range findTags(body, "link") {
+		rels := strings.Fields(strings.ToLower(t.attrs["rel"]))
+		href, err := resolve(pageURL, t.attrs["href"])
+		if err != nil || t.attrs["href"] == "" {
+			continue
+		}
+		for _, rel := range rels {
+			switch {
+			case rel == "canonical" && m.Canonical == "":
+				m.Canonical = href
+			case rel == "alternate" && t.attrs["hreflang"] != "":
+				if m.Hreflang == nil {
+					m.Hreflang = map[string]string{}
+				}
+				m.Hreflang[strings.ToLower(t.attrs["hreflang"])] = href
+			}
+		}
+	}
+
+	return m
+}
+
+// recordMeta stores the metadata of a fetched page, if audited
+func (cc *Creeper) recordMeta(url, body string) {
+	if !cc.SEO {
+		return
+	}
+	m := extractMeta(url, body)
+
+	cc.muSeen.Lock()
+	cc.metadata[url] = m
+	cc.muSeen.Unlock()
+}
+
+// AuditSEO checks the metadata of the crawled pages
+func (cc *Creeper) AuditSEO() *SEOAudit {
+	cc.muSeen.Lock()
+	meta := map[string]PageMeta{}
+	for u, m := range cc.metadata {
+		meta[u] = m
+	}
+	cc.muSeen.Unlock()
+
+	pages := []string{}
+	for u := range meta {
+		pages = append(pages, u)
+	}
+	sort.Strings(pages)
+
+	a := &SEOAudit{
+		MissingTitles:         []string{},
+		DuplicateTitles:       map[string][]string{},
+		LongTitles:            []string{},
+		MissingDescriptions:   []string{},
+		DuplicateDescriptions: map[string][]string{},
+		MissingH1:             []string{},
+		MultipleH1:            []string{},
+		HreflangNotReturned:   []HreflangLink{},
+	}
+
+	titles := map[string][]string{}
+	descriptions := map[string][]string{}
+	for _, u := range pages {
+		m := meta[u]
+		switch {
+		case m.Title == "":
+			a.MissingTitles = append(a.MissingTitles, u)
+		case utf8.RuneCountInString(m.Title) > maxTitleLength:
+			a.LongTitles = append(a.LongTitles, u)
+		}
+		if m.Title != "" {
+			titles[m.Title] = append(titles[m.Title], u)
+		}
+
+		if m.Description == "" {
+			a.MissingDescriptions = append(a.MissingDescriptions, u)
+		} else {
+			descriptions[m.Description] = append(descriptions[m.Description], u)
+		}
+
+		switch {
+		case len(m.H1) == 0:
+			a.MissingH1 = append(a.MissingH1, u)
+		case len(m.H1) > 1:
+			a.MultipleH1 = append(a.MultipleH1, u)
+		}
+
+		langs := []string{}
+		for lang := range m.Hreflang {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			to := m.Hreflang[lang]
+			alt, ok := meta[to]
+			if to == u || !ok {
+				continue
+			}
+			if !linksTo(alt.Hreflang, u) {
+				a.HreflangNotReturned = append(a.HreflangNotReturned, HreflangLink{
+					From: u,
+					Lang: lang,
+					To:   to,
+				})
+			}
+		}
+	}
+
+	for title, urls := range titles {
+		if len(urls) > 1 {
+			a.DuplicateTitles[title] = urls
+		}
+	}
+	for description, urls := range descriptions {
+		if len(urls) > 1 {
+			a.DuplicateDescriptions[description] = urls
+		}
+	}
+
+	return a
+}
+
+// linksTo returns true if any of the alternate pages is the given url
+func linksTo(hreflang map[string]string, url string) bool {
+	for _, u := range hreflang {
+		if u == url {
+			return true
+		}
+	}
+	return false
+}
+
+// Display displays the metadata issues
+func (a *SEOAudit) Display(w io.Writer) {
+	fmt.Fprint(w, "\n👍 SEO audit 👍\n\n")
+
+	displayURLs(w, "pages without title", a.MissingTitles)
+	displayGroups(w, "titles used by more pages", a.DuplicateTitles)
+	displayURLs(w, fmt.Sprintf("pages with titles longer than %d characters", maxTitleLength), a.LongTitles)
+	displayURLs(w, "pages without description", a.MissingDescriptions)
+	displayGroups(w, "descriptions used by more pages", a.DuplicateDescriptions)
+	displayURLs(w, "pages without h1", a.MissingH1)
+	displayURLs(w, "pages with more h1", a.MultipleH1)
+	fmt.Fprintf(w, "   hreflang links not returned = %d\n", len(a.HreflangNotReturned))
+	for _, l := range a.HreflangNotReturned {
+		fmt.Fprintf(w, "      - [%s] -> %s [%s]\n", l.From, l.Lang, l.To)
+	}
+	fmt.Fprintln(w)
+}
+
+func displayURLs(w io.Writer, title string, urls []string) {
+	fmt.Fprintf(w, "   %s = %d\n", title, len(urls))
+	for _, u := range urls {
+		fmt.Fprintf(w, "      - [%s]\n", u)
+	}
+}
+
+func displayGroups(w io.Writer, title string, groups map[string][]string) {
+	keys := []string{}
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "   %s = %d\n", title, len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(w, "      * %q\n", k)
+		for _, u := range groups[k] {
+			fmt.Fprintf(w, "         [%s]\n", u)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMeta(t *testing.T) {
+	body := `<!DOCTYPE html>
+<html class="no-js" lang="en" dir="ltr">
+  <head>
+    <title>Mmmmm &ndash; It's time for a new kind of bank</title>
+    <meta charset="utf-8">
+    <meta name="description" content="Banking   made easy">
+    <link rel="canonical" href="/about">
+    <link rel="alternate" hreflang="de" href="https://mmmmm.com/de/about" />
+    <link rel='alternate' hreflang='EN' href='https://mmmmm.com/about'>
+  </head>
+  <body>
+    <h1>About <em>us</em></h1>
+    <h1 class="second">Careers</h1>
+  </body>
+</html>`
+
+	want := PageMeta{
+		Title:       "Mmmmm – It's time for a new kind of bank",
+		Description: "Banking made easy",
+		H1:          []string{"About us", "Careers"},
+		Canonical:   "https://mmmmm.com/about",
+		Lang:        "en",
+		Hreflang: map[string]string{
+			"de": "https://mmmmm.com/de/about",
+			"en": "https://mmmmm.com/about",
+		},
+	}
+	if got := extractMeta("https://mmmmm.com/about", body); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestExtractMeta = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreeper_AuditSEO(t *testing.T) {
+	cc := &Creeper{
+		metadata: map[string]PageMeta{
+			"https://mmmmm.com": PageMeta{
+				Title:       "Mmmmm",
+				Description: "Banking made easy",
+				H1:          []string{"Mmmmm"},
+				Hreflang: map[string]string{
+					"en": "https://mmmmm.com",
+					"de": "https://mmmmm.com/de",
+				},
+			},
+			"https://mmmmm.com/de": PageMeta{
+				Title:       "Mmmmm",
+				Description: "Banking made easy",
+				H1:          []string{"Mmmmm", "Bank"},
+			},
+			"https://mmmmm.com/about": PageMeta{
+				Title: "About Mmmmm, the bank with the best app in the whole wide world",
+			},
+			"https://mmmmm.com/faq": PageMeta{
+				Description: "Questions",
+				H1:          []string{"FAQ"},
+			},
+		},
+	}
+
+	want := &SEOAudit{
+		MissingTitles: []string{"https://mmmmm.com/faq"},
+		DuplicateTitles: map[string][]string{
+			"Mmmmm": []string{"https://mmmmm.com", "https://mmmmm.com/de"},
+		},
+		LongTitles:          []string{"https://mmmmm.com/about"},
+		MissingDescriptions: []string{"https://mmmmm.com/about"},
+		DuplicateDescriptions: map[string][]string{
+			"Banking made easy": []string{"https://mmmmm.com", "https://mmmmm.com/de"},
+		},
+		MissingH1:  []string{"https://mmmmm.com/about"},
+		MultipleH1: []string{"https://mmmmm.com/de"},
+		HreflangNotReturned: []HreflangLink{
+			{From: "https://mmmmm.com", Lang: "de", To: "https://mmmmm.com/de"},
+		},
+	}
+	if got := cc.AuditSEO(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestCreeper_AuditSEO = %+v, want %+v", got, want)
+	}
+}
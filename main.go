@@ -4,33 +4,348 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/tamarakaufler/go-crawler/crawler"
+	"github.com/tamarakaufler/go-crawler/server"
 )
 
 var baseURL string
 var depth int
+var deterministic bool
+var maxDuration time.Duration
+var maxPages int
+var maxBytes int64
+var retries int
+var retryBackoff time.Duration
+var stateDir string
+var resumeDir string
+var sinceDir string
+var analysis bool
+var analysisTop int
+var analysisMaxLinks int
+var ranking bool
+var damping float64
+var rankIterations int
+var hits bool
+var duplicates bool
+var similarityThreshold float64
+var seo bool
+var assets bool
+var linkContext bool
+var fragments bool
+var showProgress bool
+var progressInterval time.Duration
+var format string
+var addr string
+var metricsAddr string
+var metrics *crawler.Metrics
+var logLevel string
+var configFile string
+var profile string
+var logFormat string
+var maxJobs int
+var basicAuth string
+var bearerToken string
+var authHeader string
+var cookiesFile string
+var loginURL string
+var loginForm string
+var cookieReport bool
+var saveCookies bool
+var source string
 
 func init() {
+	flag.StringVar(&configFile, "config", "", "JSON, YAML or TOML config file with the options named as the flags and their profiles. Default is none.")
+	flag.StringVar(&profile, "profile", "", "Profile of the config file to use. Default is none.")
 	flag.StringVar(&baseURL, "url", "https://docs.docker.com", "Base URL where the crawler starts. Default is https://docs.docker.com .")
 	flag.IntVar(&depth, "depth", 3, "How deep the crawler goes. Up to 10 levels are supported. Default is 3.")
+	flag.BoolVar(&deterministic, "deterministic", false, "Produce identical output for identical site content, e.g. for diffing crawls. Default is false.")
+	flag.DurationVar(&maxDuration, "max-duration", 0, "Stop fetching new pages after the given time, e.g. 10m. Default is no limit.")
+	flag.IntVar(&maxPages, "max-pages", 0, "Stop fetching new pages after the given number of pages. Default is no limit.")
+	flag.Int64Var(&maxBytes, "max-bytes", 0, "Stop fetching new pages after the given number of downloaded bytes. Default is no limit.")
+	flag.IntVar(&retries, "retries", 0, "Retry page requests failing without a response, with 429 or a 5xx status, up to the given number of times. Default is 0.")
+	flag.DurationVar(&retryBackoff, "retry-backoff", 500*time.Millisecond, "How long to wait before the first retry of a page request, doubled for every further retry. Default is 500ms.")
+	flag.StringVar(&stateDir, "state", "", "Directory where the crawl state is periodically saved. Default is no saving.")
+	flag.StringVar(&resumeDir, "resume", "", "Directory with a saved crawl state to continue from. The state keeps being saved there.")
+	flag.StringVar(&sinceDir, "since", "", "Directory with the saved state of a previous crawl. Unmodified pages are not downloaded again and changes are reported.")
+	flag.BoolVar(&analysis, "analysis", false, "Display orphan, dead-end and most linked pages, pages with too many links and strongly connected pages. Default is false.")
+	flag.IntVar(&analysisTop, "analysis-top", 10, "Number of most linked pages in the analysis. Default is 10.")
+	flag.IntVar(&analysisMaxLinks, "analysis-max-links", 20, "Number of links above which a page has too many links in the analysis. Default is 20.")
+	flag.BoolVar(&ranking, "ranking", false, "Rank pages by PageRank of the internal links. Default is false.")
+	flag.Float64Var(&damping, "damping", 0.85, "Damping factor of PageRank. Default is 0.85.")
+	flag.IntVar(&rankIterations, "rank-iterations", 50, "Number of iterations of the ranking. Default is 50.")
+	flag.BoolVar(&hits, "hits", false, "Add HITS hub and authority scores to the ranking. Default is false.")
+	flag.BoolVar(&duplicates, "duplicates", false, "Display pages with identical or similar visible text. Default is false.")
+	flag.Float64Var(&similarityThreshold, "similarity", 0.9, "Similarity, from 0 to 1, above which pages are near duplicates. Default is 0.9.")
+	flag.BoolVar(&seo, "seo", false, "Record titles, descriptions, h1s, canonical urls and hreflang links of the pages and display their audit. Default is false.")
+	flag.BoolVar(&assets, "assets", false, "Collect images, scripts, stylesheets, icons, frames, media and css url() references of the pages and check them. Default is false.")
+	flag.BoolVar(&linkContext, "link-context", false, "Record anchor texts, titles, rel values and page regions of the links and display links with generic or empty anchor texts. Default is false.")
+	flag.BoolVar(&fragments, "fragments", false, "Check that links to fragments of the pages point at existing id or name anchors. Default is false.")
+	flag.BoolVar(&showProgress, "progress", false, "Display the progress of the crawl, refreshed on a terminal and logged otherwise. Default is false.")
+	flag.DurationVar(&progressInterval, "progress-interval", 10*time.Second, "How often the progress is logged when stderr is not a terminal. Default is 10s.")
+	flag.StringVar(&format, "format", crawler.FormatText, "Output format, text or json, for export also csv, dot or sitemap. Default is text.")
+	flag.StringVar(&addr, "addr", ":8080", "Address the serve command listens on. Default is :8080.")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Address Prometheus metrics are exposed on, at /metrics, e.g. :9090. Default is none.")
+	flag.StringVar(&logLevel, "log-level", "info", "Log level, debug, info, warn or error. Default is info.")
+	flag.StringVar(&logFormat, "log-format", crawler.FormatText, "Log format, text or json. Default is text.")
+	flag.IntVar(&maxJobs, "max-jobs", 2, "Maximum number of crawl jobs the serve command runs at a time. Default is 2.")
+	flag.StringVar(&basicAuth, "basic-auth", "", "Basic auth credentials sent to the crawled site, as user:password. Default is none.")
+	flag.StringVar(&bearerToken, "bearer-token", "", "Bearer token sent to the crawled site. Default is none.")
+	flag.StringVar(&authHeader, "auth-header", "", "Header sent to the crawled site, as Name: value. Default is none.")
+	flag.StringVar(&cookiesFile, "cookies", "", "Netscape cookies.txt file with cookies sent with the requests. Default is none.")
+	flag.StringVar(&loginURL, "login-url", "", "URL the login-form is posted to before the crawl, to get the session cookies. Default is none.")
+	flag.StringVar(&loginForm, "login-form", "", "Url encoded form fields posted to the login-url, e.g. user=me&password=secret. Default is none.")
+	flag.BoolVar(&cookieReport, "cookie-report", false, "Display the cookies set by the site, with their domains, paths and flags. Default is false.")
+	flag.BoolVar(&saveCookies, "save-cookies", false, "Save the cookies, with their values, in the crawl state, for a resumed crawl. Default is false.")
+	flag.StringVar(&source, "source", "", "Local directory, .zip or .tar.gz of a static site crawled as the site of the url, e.g. a build output. Default is none.")
 }
 
 func main() {
+	flag.Usage = usage
 	flag.Parse()
 
-	var cc crawler.Crawler
+	cmd, args, err := parseCommand(flag.CommandLine, flag.Args())
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n\n", err)
+		if cmd != nil {
+			cmd.usage(flag.CommandLine)
+		} else {
+			usage()
+		}
+		os.Exit(2)
+	}
+	if err := applyConfig(flag.CommandLine, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(2)
+	}
+	setupLogging()
+	serveMetrics()
+
+	cmd.run(args)
+}
 
-	c := &crawler.Creeper{
-		BaseURL: baseURL,
-		Depth:   int8(depth),
+// newCreeper sets up a crawler for the given URL from the common flags
+func newCreeper(url string) *crawler.Creeper {
+	var auth *crawler.Auth
+	if basicAuth != "" || bearerToken != "" || authHeader != "" || cookiesFile != "" || loginURL != "" {
+		auth = &crawler.Auth{
+			BasicAuth:   basicAuth,
+			BearerToken: bearerToken,
+			Header:      authHeader,
+			CookiesFile: cookiesFile,
+			LoginURL:    loginURL,
+			LoginForm:   loginForm,
+		}
 	}
 
-	cc = c
+	return &crawler.Creeper{
+		BaseURL:          url,
+		Depth:            int8(depth),
+		Deterministic:    deterministic,
+		MaxDuration:      maxDuration,
+		MaxPages:         maxPages,
+		MaxBytes:         maxBytes,
+		Retries:          retries,
+		RetryBackoff:     retryBackoff,
+		Analysis:         analysis,
+		AnalysisTop:      analysisTop,
+		AnalysisMaxLinks: analysisMaxLinks,
+		Ranking:          ranking,
+		Damping:          damping,
+		RankIterations:   rankIterations,
+		HITS:             hits,
+		Duplicates:       duplicates,
+		Similarity:       similarityThreshold,
+		SEO:              seo,
+		Assets:           assets,
+		LinkContext:      linkContext,
+		Fragments:        fragments,
+		ShowProgress:     showProgress,
+		ProgressInterval: progressInterval,
+		Metrics:          metrics,
+		Format:           format,
+		Auth:             auth,
+		CookieReport:     cookieReport,
+		SaveCookies:      saveCookies,
+		Source:           source,
+	}
+}
 
-	err := cc.Run()
+// setupLogging sets up the default structured logger, writing to stderr
+func setupLogging() {
+	logger, err := crawler.NewLogger(os.Stderr, logLevel, logFormat)
 	if err != nil {
-		fmt.Printf("ERROR: %v\n", err)
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+}
+
+// serveMetrics exposes the metrics of the crawls, if requested
+func serveMetrics() {
+	if metricsAddr == "" {
 		return
 	}
+	metrics = crawler.NewMetrics()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	go func() {
+		if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+			slog.Error("metrics not served", "addr", metricsAddr, "error", err)
+		}
+	}()
+}
+
+// crawl crawls the site of the given or default url and displays
+// its sitemap and reports
+func crawl(args []string) {
+	c := newCreeper(urlArg(args))
+	c.StateDir = stateDir
+	c.PreviousStateDir = sinceDir
+	if resumeDir != "" {
+		c.StateDir = resumeDir
+		c.Resume = true
+		if flagGiven(flag.CommandLine, "depth") {
+			slog.Warn("depth ignored, resuming to the depth of the saved crawl", "depth", depth)
+		}
+	}
+
+	var cc crawler.Crawler = c
+	if err := cc.Run(); err != nil {
+		slog.Error("crawl failed", "error", err)
+		os.Exit(2)
+	}
+}
+
+// check crawls a site and displays its broken links,
+// exiting with 1 if there are any
+func check(args []string) {
+	if err := reportFormat(format); err != nil {
+		slog.Error("links not checked", "error", err)
+		os.Exit(2)
+	}
+	c := newCreeper(urlArg(args))
+	c.Out = ioutil.Discard
+	if err := c.Run(); err != nil {
+		slog.Error("crawl failed", "error", err)
+		os.Exit(2)
+	}
+
+	r := c.Check()
+	writeReport(os.Stdout, r, format)
+	if !r.Empty() {
+		os.Exit(1)
+	}
+}
+
+// export writes a saved crawl in the requested format
+func export(args []string) {
+	c := newCreeper("")
+	if err := c.Load(args[0]); err != nil {
+		slog.Error("crawl not loaded", "error", err)
+		os.Exit(2)
+	}
+	if err := c.Export(os.Stdout, format); err != nil {
+		slog.Error("crawl not exported", "error", err)
+		os.Exit(2)
+	}
+}
+
+// sitemap crawls a site and writes its XML sitemap
+func sitemap(args []string) {
+	c := newCreeper(urlArg(args))
+	c.Out = ioutil.Discard
+	if err := c.Run(); err != nil {
+		slog.Error("crawl failed", "error", err)
+		os.Exit(2)
+	}
+	if err := c.WriteSitemap(os.Stdout); err != nil {
+		slog.Error("sitemap not written", "error", err)
+		os.Exit(2)
+	}
+}
+
+// urlArg returns the url given as the argument of a command,
+// the url flag if there is none
+func urlArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return baseURL
+}
+
+// diff compares two saved crawls, exiting with 1 if they differ
+func diff(args []string) {
+	d, err := crawler.DiffCrawls(args[0], args[1])
+	if err == nil {
+		err = writeReport(os.Stdout, d, format)
+	}
+	if err != nil {
+		slog.Error("crawls not compared", "error", err)
+		os.Exit(2)
+	}
+	if !d.Empty() {
+		os.Exit(1)
+	}
+}
+
+// compare crawls two sites, e.g. staging and production, and compares
+// their structure, exiting with 1 if they differ
+func compare(args []string) {
+	if err := reportFormat(format); err != nil {
+		slog.Error("sites not compared", "error", err)
+		os.Exit(2)
+	}
+	c, err := crawler.CompareSites(newCreeper(args[0]), newCreeper(args[1]))
+	if err == nil {
+		err = writeReport(os.Stdout, c, format)
+	}
+	if err != nil {
+		slog.Error("sites not compared", "error", err)
+		os.Exit(2)
+	}
+	if !c.Empty() {
+		os.Exit(1)
+	}
+}
+
+// path displays the shortest click paths to a page of a saved crawl,
+// exiting with 1 if there is none
+func path(args []string) {
+	from := ""
+	if len(args) > 2 {
+		from = args[2]
+	}
+
+	paths, err := crawler.PathsInCrawl(args[0], from, args[1], 0)
+	if err == nil {
+		if paths == nil {
+			paths = [][]string{}
+		}
+		err = writeReport(os.Stdout, clickPaths(paths), format)
+	}
+	if err != nil {
+		slog.Error("paths not found", "error", err)
+		os.Exit(2)
+	}
+	if len(paths) == 0 {
+		os.Exit(1)
+	}
+}
+
+// serve runs crawl jobs submitted over HTTP
+func serve(args []string) {
+	slog.Info("serving crawl jobs", "addr", addr, "max_jobs", maxJobs)
+	err := http.ListenAndServe(addr, &server.Server{MaxJobs: maxJobs, Metrics: metrics})
+	slog.Error("crawl jobs not served", "addr", addr, "error", err)
+	os.Exit(2)
 }
@@ -0,0 +1,406 @@
+// Package server exposes the crawler over HTTP. Crawl jobs are submitted,
+// polled, cancelled and their results retrieved through a REST API.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tamarakaufler/go-crawler/crawler"
+)
+
+const (
+	defaultMaxJobs = 2
+	defaultDepth   = 3
+	defaultJobTTL  = time.Hour
+)
+
+// Statuses of a crawl job
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// contentTypes are the content types of the result formats
+var contentTypes = map[string]string{
+	crawler.FormatJSON:    "application/json",
+	crawler.FormatText:    "text/plain; charset=utf-8",
+	crawler.FormatCSV:     "text/csv; charset=utf-8",
+	crawler.FormatDOT:     "text/vnd.graphviz; charset=utf-8",
+	crawler.FormatSitemap: "application/xml; charset=utf-8",
+}
+
+var (
+	ErrJobNotFound    = errors.New("Job not found")
+	ErrJobNotFinished = errors.New("Job has not finished yet")
+	ErrJobFinished    = errors.New("Job has already finished")
+)
+
+// JobRequest describes a crawl job. The crawl stays within the site
+// of the url, starting from the url and the seeds, pages of the site,
+// and following only links within the path prefixes of the scope.
+type JobRequest struct {
+	URL           string   `json:"url"`
+	Seeds         []string `json:"seeds"`
+	Scope         []string `json:"scope"`
+	Depth         int      `json:"depth"`
+	Deterministic bool     `json:"deterministic"`
+	// MaxDuration is a duration like 90s or 5m
+	MaxDuration string `json:"max_duration"`
+	MaxPages    int    `json:"max_pages"`
+	MaxBytes    int64  `json:"max_bytes"`
+	Analysis    bool   `json:"analysis"`
+	Ranking     bool   `json:"ranking"`
+	HITS        bool   `json:"hits"`
+	Duplicates  bool   `json:"duplicates"`
+	SEO         bool   `json:"seo"`
+	Assets      bool   `json:"assets"`
+	LinkContext bool   `json:"link_context"`
+	Fragments   bool   `json:"fragments"`
+	Cookies     bool   `json:"cookies"`
+}
+
+// JobStatus is the state of a crawl job
+type JobStatus struct {
+	ID       string           `json:"id"`
+	URL      string           `json:"url"`
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Created  time.Time        `json:"created"`
+	Started  *time.Time       `json:"started,omitempty"`
+	Finished *time.Time       `json:"finished,omitempty"`
+	Progress crawler.Progress `json:"progress"`
+}
+
+type job struct {
+	id       string
+	url      string
+	cc       *crawler.Creeper
+	status   string
+	err      error
+	created  time.Time
+	started  time.Time
+	finished time.Time
+	mu       sync.Mutex
+}
+
+// Server runs crawl jobs, at most MaxJobs (default 2) at a time.
+// Finished jobs are kept for JobTTL (default 1h). If Metrics is set,
+// it collects metrics of all the jobs
+type Server struct {
+	MaxJobs int
+	JobTTL  time.Duration
+	Metrics *crawler.Metrics
+
+	jobs   map[string]*job
+	nextID int
+	slots  chan struct{}
+	once   sync.Once
+	mu     sync.Mutex
+}
+
+// init prepares the server on the first request
+func (s *Server) init() {
+	s.once.Do(func() {
+		if s.MaxJobs <= 0 {
+			s.MaxJobs = defaultMaxJobs
+		}
+		if s.JobTTL <= 0 {
+			s.JobTTL = defaultJobTTL
+		}
+		s.jobs = map[string]*job{}
+		s.slots = make(chan struct{}, s.MaxJobs)
+	})
+}
+
+// ServeHTTP routes the API requests:
+//
+//	POST   /jobs              submits a crawl job
+//	GET    /jobs              lists the jobs
+//	GET    /jobs/{id}         returns the status and progress of a job
+//	DELETE /jobs/{id}         cancels a job
+//	GET    /jobs/{id}/result  returns the result of a finished job,
+//	                          in the format given by
+//	                          ?format=json|text|csv|dot|sitemap
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.init()
+	s.expire()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "jobs" || len(parts) > 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		s.submit(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.list(w)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		s.status(w, parts[1])
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		s.cancel(w, parts[1])
+	case len(parts) == 3 && parts[2] == "result" && r.Method == http.MethodGet:
+		s.result(w, parts[1], r.URL.Query().Get("format"))
+	case len(parts) <= 3:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// submit queues a crawl job and starts it once a slot is free
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cc, err := newCreeper(req)
+	if err == nil {
+		err = cc.Validate()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cc.Metrics = s.Metrics
+
+	s.mu.Lock()
+	s.nextID++
+	j := &job{
+		id:      strconv.Itoa(s.nextID),
+		url:     req.URL,
+		cc:      cc,
+		status:  StatusQueued,
+		created: time.Now(),
+	}
+	s.jobs[j.id] = j
+	s.mu.Unlock()
+
+	go s.run(j)
+
+	writeJSON(w, http.StatusAccepted, j.jobStatus())
+}
+
+// newCreeper sets up a crawler for a job
+func newCreeper(req JobRequest) (*crawler.Creeper, error) {
+	if req.URL == "" {
+		return nil, crawler.ErrNoUrlProvided
+	}
+	if req.Depth <= 0 {
+		req.Depth = defaultDepth
+	}
+	var maxDuration time.Duration
+	if req.MaxDuration != "" {
+		d, err := time.ParseDuration(req.MaxDuration)
+		if err != nil {
+			return nil, fmt.Errorf("max_duration: %v", err)
+		}
+		maxDuration = d
+	}
+
+	return &crawler.Creeper{
+		BaseURL:       req.URL,
+		Seeds:         req.Seeds,
+		Scope:         req.Scope,
+		Depth:         int8(req.Depth),
+		Deterministic: req.Deterministic,
+		MaxDuration:   maxDuration,
+		MaxPages:      req.MaxPages,
+		MaxBytes:      req.MaxBytes,
+		Analysis:      req.Analysis,
+		Ranking:       req.Ranking,
+		HITS:          req.HITS,
+		Duplicates:    req.Duplicates,
+		SEO:           req.SEO,
+		Assets:        req.Assets,
+		LinkContext:   req.LinkContext,
+		Fragments:     req.Fragments,
+		CookieReport:  req.Cookies,
+	}, nil
+}
+
+// run crawls the site of a job, waiting for a free slot first
+func (s *Server) run(j *job) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	j.mu.Lock()
+	if j.status == StatusCancelled {
+		j.mu.Unlock()
+		return
+	}
+	j.status = StatusRunning
+	j.started = time.Now()
+	j.mu.Unlock()
+
+	err := j.cc.Crawl()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.finished = time.Now()
+	switch {
+	case err != nil:
+		j.status = StatusFailed
+		j.err = err
+	case j.status != StatusCancelled:
+		j.status = StatusDone
+	}
+}
+
+// list returns all jobs, in the order of their submission
+func (s *Server) list(w http.ResponseWriter) {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	statuses := []JobStatus{}
+	for _, j := range jobs {
+		statuses = append(statuses, j.jobStatus())
+	}
+	sort.Slice(statuses, func(i, k int) bool {
+		a, _ := strconv.Atoi(statuses[i].ID)
+		b, _ := strconv.Atoi(statuses[k].ID)
+		return a < b
+	})
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) status(w http.ResponseWriter, id string) {
+	j, ok := s.job(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrJobNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, j.jobStatus())
+}
+
+// cancel cancels a queued or running job. Pages of a running job
+// crawled so far remain available as its result.
+func (s *Server) cancel(w http.ResponseWriter, id string) {
+	j, ok := s.job(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrJobNotFound)
+		return
+	}
+
+	j.mu.Lock()
+	switch j.status {
+	case StatusQueued:
+		j.status = StatusCancelled
+		j.finished = time.Now()
+		j.cc.Stop()
+	case StatusRunning:
+		j.status = StatusCancelled
+		j.cc.Stop()
+	default:
+		j.mu.Unlock()
+		writeError(w, http.StatusConflict, ErrJobFinished)
+		return
+	}
+	j.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, j.jobStatus())
+}
+
+// result writes the sitemap and reports, or the export, of a finished
+// job. A job cancelled before it started has no pages.
+func (s *Server) result(w http.ResponseWriter, id, format string) {
+	j, ok := s.job(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrJobNotFound)
+		return
+	}
+
+	j.mu.Lock()
+	finished := !j.finished.IsZero() && j.status != StatusFailed
+	j.mu.Unlock()
+	if !finished {
+		writeError(w, http.StatusConflict, ErrJobNotFinished)
+		return
+	}
+
+	if format == "" {
+		format = crawler.FormatJSON
+	}
+	ct, ok := contentTypes[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, crawler.ErrUnknownFormat)
+		return
+	}
+	w.Header().Set("Content-Type", ct)
+	j.cc.Export(w, format)
+}
+
+// expire forgets jobs finished more than JobTTL ago
+func (s *Server) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, j := range s.jobs {
+		j.mu.Lock()
+		expired := !j.finished.IsZero() && time.Since(j.finished) > s.JobTTL
+		j.mu.Unlock()
+		if expired {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *Server) job(id string) (*job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+func (j *job) jobStatus() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	st := JobStatus{
+		ID:       j.id,
+		URL:      j.url,
+		Status:   j.status,
+		Created:  j.created,
+		Progress: j.cc.Progress(),
+	}
+	if j.err != nil {
+		st.Error = j.err.Error()
+	}
+	if !j.started.IsZero() {
+		started := j.started
+		st.Started = &started
+	}
+	if !j.finished.IsZero() {
+		finished := j.finished
+		st.Finished = &finished
+	}
+	return st
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamarakaufler/go-crawler/crawler/crawlertest"
+)
+
+const testSite = "https://mmmmm.com"
+
+var mockSite = crawlertest.Transport{Handler: crawlertest.Handler(nil)}
+
+// siteTransport serves the mock site to the crawler,
+// blocking while a request is pending on release
+type siteTransport struct {
+	release chan struct{}
+}
+
+func (t siteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.release != nil {
+		<-t.release
+	}
+	return mockSite.RoundTrip(r)
+}
+
+func useTransport(t http.RoundTripper) func() {
+	def := http.DefaultTransport
+	http.DefaultTransport = t
+	return func() {
+		http.DefaultTransport = def
+	}
+}
+
+func do(t *testing.T, s *Server, method, path, body string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, path, err, rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+func waitFor(t *testing.T, s *Server, id, status string) JobStatus {
+	t.Helper()
+	var st JobStatus
+	for i := 0; i < 200; i++ {
+		do(t, s, http.MethodGet, "/jobs/"+id, "", &st)
+		if st.Status == status {
+			return st
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s is %s, want %s", id, st.Status, status)
+	return st
+}
+
+func TestServer_job(t *testing.T) {
+	defer useTransport(siteTransport{})()
+	s := &Server{}
+
+	var st JobStatus
+	if code := do(t, s, http.MethodPost, "/jobs", `{"url": "`+testSite+`", "depth": 2, "deterministic": true}`, &st); code != http.StatusAccepted {
+		t.Fatalf("POST /jobs = %d, want %d", code, http.StatusAccepted)
+	}
+	st = waitFor(t, s, st.ID, StatusDone)
+	if st.Progress.PagesFetched != 5 || st.Progress.EndReason != "completed" {
+		t.Errorf("job progress = %+v, want 5 pages, completed", st.Progress)
+	}
+
+	var result struct {
+		Pages []struct {
+			URL string `json:"url"`
+		} `json:"pages"`
+	}
+	if code := do(t, s, http.MethodGet, "/jobs/"+st.ID+"/result", "", &result); code != http.StatusOK {
+		t.Fatalf("GET result = %d, want %d", code, http.StatusOK)
+	}
+	if len(result.Pages) != 5 {
+		t.Errorf("result pages = %+v, want 5 pages", result.Pages)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+st.ID+"/result?format=text", nil))
+	if !strings.Contains(rec.Body.String(), "SiteMap display") {
+		t.Errorf("text result = %q, want the sitemap", rec.Body.String())
+	}
+
+	exports := []struct {
+		format      string
+		contentType string
+		want        string
+	}{
+		{"csv", "text/csv; charset=utf-8", "url,depth,status,links\n" + testSite + ",0,200,2\n"},
+		{"dot", "text/vnd.graphviz; charset=utf-8", `"` + testSite + `" -> "` + testSite + `/about";`},
+		{"sitemap", "application/xml; charset=utf-8", "<loc>" + testSite + "/faq</loc>"},
+	}
+	for _, e := range exports {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+st.ID+"/result?format="+e.format, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != e.contentType {
+			t.Errorf("%s result = %d %s, want %d %s", e.format, rec.Code, rec.Header().Get("Content-Type"), http.StatusOK, e.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), e.want) {
+			t.Errorf("%s result = %q, want %q", e.format, rec.Body.String(), e.want)
+		}
+	}
+	if code := do(t, s, http.MethodGet, "/jobs/"+st.ID+"/result?format=pdf", "", nil); code != http.StatusBadRequest {
+		t.Errorf("GET result in unknown format = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var jobs []JobStatus
+	do(t, s, http.MethodGet, "/jobs", "", &jobs)
+	if len(jobs) != 1 || jobs[0].ID != st.ID {
+		t.Errorf("GET /jobs = %+v, want job %s", jobs, st.ID)
+	}
+	if code := do(t, s, http.MethodDelete, "/jobs/"+st.ID, "", nil); code != http.StatusConflict {
+		t.Errorf("DELETE finished job = %d, want %d", code, http.StatusConflict)
+	}
+}
+
+func TestServer_job_seeds(t *testing.T) {
+	defer useTransport(siteTransport{})()
+	s := &Server{}
+
+	var st JobStatus
+	body := `{"url": "` + testSite + `", "seeds": ["` + testSite + `/info", "` + testSite + `/careers"], "scope": ["/info", "/careers"], "depth": 1, "deterministic": true}`
+	if code := do(t, s, http.MethodPost, "/jobs", body, &st); code != http.StatusAccepted {
+		t.Fatalf("POST /jobs = %d, want %d", code, http.StatusAccepted)
+	}
+	waitFor(t, s, st.ID, StatusDone)
+
+	var result struct {
+		Pages []struct {
+			URL   string `json:"url"`
+			Depth int    `json:"depth"`
+		} `json:"pages"`
+	}
+	do(t, s, http.MethodGet, "/jobs/"+st.ID+"/result", "", &result)
+	got := map[string]int{}
+	for _, p := range result.Pages {
+		got[p.URL] = p.Depth
+	}
+	want := map[string]int{testSite: 0, testSite + "/info": 0, testSite + "/careers": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("result pages = %v, want %v", got, want)
+	}
+}
+
+func TestServer_cancel(t *testing.T) {
+	release := make(chan struct{})
+	defer useTransport(siteTransport{release: release})()
+	s := &Server{MaxJobs: 1}
+
+	var running, queued JobStatus
+	do(t, s, http.MethodPost, "/jobs", `{"url": "`+testSite+`"}`, &running)
+	waitFor(t, s, running.ID, StatusRunning)
+	do(t, s, http.MethodPost, "/jobs", `{"url": "`+testSite+`"}`, &queued)
+	if queued.Status != StatusQueued {
+		t.Errorf("second job is %s, want %s", queued.Status, StatusQueued)
+	}
+	if code := do(t, s, http.MethodGet, "/jobs/"+running.ID+"/result", "", nil); code != http.StatusConflict {
+		t.Errorf("GET result of running job = %d, want %d", code, http.StatusConflict)
+	}
+
+	do(t, s, http.MethodDelete, "/jobs/"+queued.ID, "", nil)
+	do(t, s, http.MethodDelete, "/jobs/"+running.ID, "", nil)
+	close(release)
+
+	st := waitFor(t, s, running.ID, StatusCancelled)
+	for st.Finished == nil {
+		time.Sleep(10 * time.Millisecond)
+		do(t, s, http.MethodGet, "/jobs/"+running.ID, "", &st)
+	}
+	if st.Progress.EndReason != "cancelled" || st.Progress.PagesFetched != 1 {
+		t.Errorf("cancelled job progress = %+v, want 1 page, cancelled", st.Progress)
+	}
+	if st = waitFor(t, s, queued.ID, StatusCancelled); st.Started != nil {
+		t.Errorf("cancelled queued job started at %v", st.Started)
+	}
+
+	var result struct {
+		EndReason string        `json:"end_reason"`
+		Pages     []interface{} `json:"pages"`
+	}
+	if code := do(t, s, http.MethodGet, "/jobs/"+queued.ID+"/result", "", &result); code != http.StatusOK {
+		t.Fatalf("GET result of cancelled queued job = %d, want %d", code, http.StatusOK)
+	}
+	if result.EndReason != "cancelled" || len(result.Pages) != 0 {
+		t.Errorf("cancelled queued job result = %+v, want no pages, cancelled", result)
+	}
+	if code := do(t, s, http.MethodDelete, "/jobs/"+queued.ID, "", nil); code != http.StatusConflict {
+		t.Errorf("DELETE cancelled job = %d, want %d", code, http.StatusConflict)
+	}
+}
+
+func TestServer_expire(t *testing.T) {
+	defer useTransport(siteTransport{})()
+	s := &Server{JobTTL: 50 * time.Millisecond}
+
+	var st JobStatus
+	do(t, s, http.MethodPost, "/jobs", `{"url": "`+testSite+`", "depth": 1}`, &st)
+	waitFor(t, s, st.ID, StatusDone)
+	time.Sleep(100 * time.Millisecond)
+
+	if code := do(t, s, http.MethodGet, "/jobs/"+st.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET expired job = %d, want %d", code, http.StatusNotFound)
+	}
+	var jobs []JobStatus
+	if do(t, s, http.MethodGet, "/jobs", "", &jobs); len(jobs) != 0 {
+		t.Errorf("GET /jobs = %+v, want no jobs", jobs)
+	}
+}
+
+func TestServer_errors(t *testing.T) {
+	s := &Server{}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"invalid json", http.MethodPost, "/jobs", `{"url":`, http.StatusBadRequest},
+		{"no url", http.MethodPost, "/jobs", `{"depth": 2}`, http.StatusBadRequest},
+		{"invalid url", http.MethodPost, "/jobs", `{"url": "mmmmm.com"}`, http.StatusBadRequest},
+		{"url with a path", http.MethodPost, "/jobs", `{"url": "` + testSite + `/docs"}`, http.StatusBadRequest},
+		{"seed on another site", http.MethodPost, "/jobs", `{"url": "` + testSite + `", "seeds": ["https://notthisone.com/about"]}`, http.StatusBadRequest},
+		{"scope without a slash", http.MethodPost, "/jobs", `{"url": "` + testSite + `", "scope": ["docs"]}`, http.StatusBadRequest},
+		{"invalid duration", http.MethodPost, "/jobs", `{"url": "` + testSite + `", "max_duration": "soon"}`, http.StatusBadRequest},
+		{"unknown job", http.MethodGet, "/jobs/42", "", http.StatusNotFound},
+		{"unknown job result", http.MethodGet, "/jobs/42/result", "", http.StatusNotFound},
+		{"unknown path", http.MethodGet, "/crawls", "", http.StatusNotFound},
+		{"unsupported method", http.MethodPut, "/jobs", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := do(t, s, tt.method, tt.path, tt.body, nil); got != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}